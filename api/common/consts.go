@@ -1,9 +1,76 @@
 package common
 
 const (
-	SPECIAL_BLOCK_KEY_PREFIX = "special_block:"
-	IP_RATE_LIMIT_KEY_PREFIX = "ip_rate_limit:"
+	SPECIAL_BLOCK_KEY_PREFIX  = "special_block:"
+	IP_RATE_LIMIT_KEY_PREFIX  = "ip_rate_limit:"
+	ADMIN_SESSION_KEY_PREFIX  = "admin_session:"  // one login session
+	ADMIN_SESSIONS_KEY_PREFIX = "admin_sessions:" // set of the session ids of a username
+	ADMIN_REFRESH_KEY_PREFIX  = "admin_refresh:"  // refresh token jti that can still be used once
+	ADMIN_REVOKED_KEY_PREFIX  = "admin_revoked:"  // revoked access token jti
 	// SPECIAL_BLOCK_LIST = map[string]struct
 )
 
+// pledge pool state, same order as the PoolState enum of the contract
+const (
+	POOL_STATE_MATCH       = "0"
+	POOL_STATE_EXECUTION   = "1"
+	POOL_STATE_FINISH      = "2"
+	POOL_STATE_LIQUIDATION = "3"
+	POOL_STATE_UNDONE      = "4"
+)
+
+// actions a wallet can take on a pool position
+const (
+	POSITION_ACTION_CLAIM              = "claim"
+	POSITION_ACTION_REFUND             = "refund"
+	POSITION_ACTION_EMERGENCY_WITHDRAW = "emergency_withdraw"
+)
+
+// admin user roles, a higher rank includes the permissions of the lower ones
+const (
+	ADMIN_ROLE_VIEWER         = "viewer"
+	ADMIN_ROLE_OPERATOR       = "operator"
+	ADMIN_ROLE_MULTISIG_ADMIN = "multisig-admin"
+)
+
+var ADMIN_ROLE_RANK = map[string]int{
+	ADMIN_ROLE_VIEWER:         1,
+	ADMIN_ROLE_OPERATOR:       2,
+	ADMIN_ROLE_MULTISIG_ADMIN: 3,
+}
+
+// pool history bucket sizes in seconds
+var POOL_HISTORY_INTERVALS = map[string]int64{
+	"5m":  5 * 60,
+	"15m": 15 * 60,
+	"1h":  3600,
+	"4h":  4 * 3600,
+	"1d":  24 * 3600,
+	"1w":  7 * 24 * 3600,
+}
+
+// POOL_HISTORY_MAX_POINTS upper bound of buckets in one pool history response
+const POOL_HISTORY_MAX_POINTS = 1000
+
+// PRICE_HISTORY_INTERVALS candle intervals of the token price history in seconds
+var PRICE_HISTORY_INTERVALS = map[string]int64{
+	"1m": 60,
+	"1h": 3600,
+	"1d": 24 * 3600,
+}
+
+// PRICE_HISTORY_MAX_POINTS upper bound of candles in one price history response
+const PRICE_HISTORY_MAX_POINTS = 1500
+
 // SPECIAL_BLOCK_LIST["asd"] = nil
+
+// admin proposal status
+const (
+	PROPOSAL_STATUS_PENDING  = "pending"  // collecting signatures
+	PROPOSAL_STATUS_APPROVED = "approved" // threshold reached, payload can be executed
+	PROPOSAL_STATUS_EXECUTED = "executed" // transaction mined
+	PROPOSAL_STATUS_EXPIRED  = "expired"  // deadline passed before execution, shown but not stored
+)
+
+// admin methods of the pledge pool contract a proposal can call
+var PROPOSAL_METHODS = []string{"setFee", "setFeeAddress", "setMinAmount", "setPause", "createPoolInfo"}
@@ -25,6 +25,57 @@ const (
 	ReceiptNotFound     = 1402
 	ParameterNotIllegal = 1403
 	BlockNotFound       = 1404
+
+	// AddressErr wallet position
+	AddressErr = 1501 //address error
+
+	// PoolIdEmpty pool history
+	PoolIdEmpty  = 1601 //pool id empty
+	TimeRangeErr = 1602 //from / to error
+	IntervalErr  = 1603 //interval error
+
+	// AdminUserExist admin users
+	AdminUserExist    = 1701 //username already used
+	AdminUserNotFound = 1702 //admin user not found
+	RoleErr           = 1703 //unknown role
+	PermissionDenied  = 1704 //role is too low
+	LastAdminErr      = 1705 //the last multisig-admin can not be removed
+	PasswordErr       = 1706 //password format error
+
+	// SiweMessageErr sign-in with ethereum
+	SiweMessageErr   = 1801 //message is not a valid EIP-4361 message for this domain
+	SiweNonceErr     = 1802 //nonce unknown, expired or used
+	SiweSignatureErr = 1803 //signature does not match the address
+	SiweNotAllowed   = 1804 //address is not a multi-sign account
+	SiweExpired      = 1805 //message expired or not valid yet
+
+	// RefreshTokenErr login session
+	RefreshTokenErr = 1901 //refresh token invalid, expired or already used
+	SessionNotFound = 1902 //session not found
+
+	// MultiSignVersionNotFound multi-sign history
+	MultiSignVersionNotFound = 2001 //multi-sign version not found
+	MultiSignVersionActive   = 2002 //multi-sign version is already active
+
+	// MultiSignAddressErr multi-sign on-chain verification
+	MultiSignAddressErr       = 2003 //not a valid checksummed address
+	MultiSignTokenNotInPool   = 2004 //sp / jp token is not the spCoin / jpCoin of a pool
+	MultiSignHashErr          = 2005 //not a valid transaction hash
+	MultiSignTxNotMined       = 2006 //transaction not found or not mined
+	MultiSignTxFailed         = 2007 //transaction reverted
+	MultiSignContractMismatch = 2008 //transaction did not create the token contract
+
+	// ProposalMethodErr admin proposal
+	ProposalMethodErr        = 2101 //method can not be proposed
+	ProposalParamsErr        = 2102 //params do not match the method
+	ProposalNotFound         = 2103 //proposal not found
+	ProposalStatusErr        = 2104 //proposal status does not allow the operation
+	ProposalExpired          = 2105 //proposal expired
+	ProposalSignatureErr     = 2106 //signature error
+	ProposalSignerNotAllowed = 2107 //signer is not a multi-sign account
+	ProposalAlreadySigned    = 2108 //signer already signed the proposal
+	ProposalThresholdErr     = 2109 //fewer multi-sign accounts than the threshold
+	ProposalTxErr            = 2110 //transaction does not execute the proposal
 )
 
 var Msg = map[int]map[int]string{
@@ -104,6 +155,181 @@ var Msg = map[int]map[int]string{
 		LangZhTw: "區塊不存在",
 		LangEn:   "block not found",
 	},
+	AddressErr: {
+		LangZh:   "地址错误",
+		LangZhTw: "地址錯誤",
+		LangEn:   "address error",
+	},
+	PoolIdEmpty: {
+		LangZh:   "poolId 不能为空",
+		LangZhTw: "poolId 不能為空",
+		LangEn:   "poolId required",
+	},
+	TimeRangeErr: {
+		LangZh:   "时间范围错误",
+		LangZhTw: "時間範圍錯誤",
+		LangEn:   "time range error",
+	},
+	IntervalErr: {
+		LangZh:   "interval 错误",
+		LangZhTw: "interval 錯誤",
+		LangEn:   "interval error",
+	},
+	AdminUserExist: {
+		LangZh:   "用户名已存在",
+		LangZhTw: "用戶名已存在",
+		LangEn:   "username already exists",
+	},
+	AdminUserNotFound: {
+		LangZh:   "用户不存在",
+		LangZhTw: "用戶不存在",
+		LangEn:   "admin user not found",
+	},
+	RoleErr: {
+		LangZh:   "角色错误",
+		LangZhTw: "角色錯誤",
+		LangEn:   "role error",
+	},
+	PermissionDenied: {
+		LangZh:   "权限不足",
+		LangZhTw: "權限不足",
+		LangEn:   "permission denied",
+	},
+	LastAdminErr: {
+		LangZh:   "不能移除最后一个 multisig-admin",
+		LangZhTw: "不能移除最後一個 multisig-admin",
+		LangEn:   "the last multisig-admin can not be removed",
+	},
+	PasswordErr: {
+		LangZh:   "密码格式错误",
+		LangZhTw: "密碼格式錯誤",
+		LangEn:   "password format error",
+	},
+	SiweMessageErr: {
+		LangZh:   "签名消息错误",
+		LangZhTw: "簽名消息錯誤",
+		LangEn:   "sign-in message error",
+	},
+	SiweNonceErr: {
+		LangZh:   "nonce 无效或已过期",
+		LangZhTw: "nonce 無效或已過期",
+		LangEn:   "nonce invalid or expired",
+	},
+	SiweSignatureErr: {
+		LangZh:   "签名错误",
+		LangZhTw: "簽名錯誤",
+		LangEn:   "signature error",
+	},
+	SiweNotAllowed: {
+		LangZh:   "地址不是多签账户",
+		LangZhTw: "地址不是多簽賬戶",
+		LangEn:   "address is not a multi-sign account",
+	},
+	SiweExpired: {
+		LangZh:   "签名消息已过期",
+		LangZhTw: "簽名消息已過期",
+		LangEn:   "sign-in message expired",
+	},
+	RefreshTokenErr: {
+		LangZh:   "refresh token 无效或已使用",
+		LangZhTw: "refresh token 無效或已使用",
+		LangEn:   "refresh token invalid or already used",
+	},
+	SessionNotFound: {
+		LangZh:   "会话不存在",
+		LangZhTw: "會話不存在",
+		LangEn:   "session not found",
+	},
+	MultiSignVersionNotFound: {
+		LangZh:   "多签版本不存在",
+		LangZhTw: "多簽版本不存在",
+		LangEn:   "multi-sign version not found",
+	},
+	MultiSignVersionActive: {
+		LangZh:   "多签版本已生效",
+		LangZhTw: "多簽版本已生效",
+		LangEn:   "multi-sign version is already active",
+	},
+	MultiSignAddressErr: {
+		LangZh:   "地址不是有效的校验和地址",
+		LangZhTw: "地址不是有效的校驗和地址",
+		LangEn:   "not a valid checksummed address",
+	},
+	MultiSignTokenNotInPool: {
+		LangZh:   "代币地址与借贷池不匹配",
+		LangZhTw: "代幣地址與借貸池不匹配",
+		LangEn:   "token address does not match the spCoin / jpCoin of a pool",
+	},
+	MultiSignHashErr: {
+		LangZh:   "交易哈希错误",
+		LangZhTw: "交易哈希錯誤",
+		LangEn:   "not a valid transaction hash",
+	},
+	MultiSignTxNotMined: {
+		LangZh:   "交易不存在或未上链",
+		LangZhTw: "交易不存在或未上鏈",
+		LangEn:   "transaction not found or not mined",
+	},
+	MultiSignTxFailed: {
+		LangZh:   "交易执行失败",
+		LangZhTw: "交易執行失敗",
+		LangEn:   "transaction reverted",
+	},
+	MultiSignContractMismatch: {
+		LangZh:   "交易未创建该代币合约",
+		LangZhTw: "交易未創建該代幣合約",
+		LangEn:   "transaction did not create the token contract",
+	},
+	ProposalMethodErr: {
+		LangZh:   "该方法不能提案",
+		LangZhTw: "該方法不能提案",
+		LangEn:   "method can not be proposed",
+	},
+	ProposalParamsErr: {
+		LangZh:   "参数与方法不匹配",
+		LangZhTw: "參數與方法不匹配",
+		LangEn:   "params do not match the method",
+	},
+	ProposalNotFound: {
+		LangZh:   "提案不存在",
+		LangZhTw: "提案不存在",
+		LangEn:   "proposal not found",
+	},
+	ProposalStatusErr: {
+		LangZh:   "提案状态错误",
+		LangZhTw: "提案狀態錯誤",
+		LangEn:   "proposal status error",
+	},
+	ProposalExpired: {
+		LangZh:   "提案已过期",
+		LangZhTw: "提案已過期",
+		LangEn:   "proposal expired",
+	},
+	ProposalSignatureErr: {
+		LangZh:   "签名错误",
+		LangZhTw: "簽名錯誤",
+		LangEn:   "signature error",
+	},
+	ProposalSignerNotAllowed: {
+		LangZh:   "签名地址不是多签账户",
+		LangZhTw: "簽名地址不是多簽賬戶",
+		LangEn:   "signer is not a multi-sign account",
+	},
+	ProposalAlreadySigned: {
+		LangZh:   "已签名",
+		LangZhTw: "已簽名",
+		LangEn:   "already signed",
+	},
+	ProposalThresholdErr: {
+		LangZh:   "多签账户数量少于门限",
+		LangZhTw: "多簽賬戶數量少於門限",
+		LangEn:   "fewer multi-sign accounts than the threshold",
+	},
+	ProposalTxErr: {
+		LangZh:   "交易未执行该提案",
+		LangZhTw: "交易未執行該提案",
+		LangEn:   "transaction does not execute the proposal",
+	},
 }
 
 func GetMsg(c int, lang int) string {
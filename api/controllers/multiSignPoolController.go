@@ -24,10 +24,11 @@ func (c *MultiSignPoolController) SetMultiSign(ctx *gin.Context) {
 		return
 	}
 
-	errCode, err := services.NewMutiSign().SetMultiSign(&req)
+	var checkErrs []response.MultiSignCheckErr
+	errCode, err := services.NewMutiSign().SetMultiSign(&req, ctx.GetString("username"), &checkErrs)
 	if errCode != statecode.CommonSuccess {
 		log.Logger.Error(err.Error())
-		res.Response(ctx, errCode, nil)
+		res.Response(ctx, errCode, checkErrs)
 		return
 	}
 
@@ -57,3 +58,69 @@ func (c *MultiSignPoolController) GetMultiSign(ctx *gin.Context) {
 	res.Response(ctx, statecode.CommonSuccess, result)
 	return
 }
+
+func (c *MultiSignPoolController) MultiSignHistory(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.MultiSignHistory{}
+	var result []response.MultiSignVersion
+
+	errCode := validate.NewMutiSign().MultiSignHistory(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode, err := services.NewMutiSign().MultiSignHistory(&result, req.ChainId)
+	if errCode != statecode.CommonSuccess {
+		log.Logger.Error(err.Error())
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *MultiSignPoolController) MultiSignDiff(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.MultiSignDiff{}
+	result := response.MultiSignDiff{}
+
+	errCode := validate.NewMutiSign().MultiSignDiff(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode, err := services.NewMutiSign().MultiSignDiff(&req, &result)
+	if errCode != statecode.CommonSuccess {
+		log.Logger.Error(err.Error())
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *MultiSignPoolController) RollbackMultiSign(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.RollbackMultiSign{}
+	result := response.MultiSignVersion{}
+
+	errCode := validate.NewMutiSign().RollbackMultiSign(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode, err := services.NewMutiSign().RollbackMultiSign(&req, ctx.GetString("username"), &result)
+	if errCode != statecode.CommonSuccess {
+		log.Logger.Error(err.Error())
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
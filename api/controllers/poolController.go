@@ -61,6 +61,27 @@ func (c *PoolController) PoolDataInfo(ctx *gin.Context) {
 	return
 }
 
+func (c *PoolController) PoolHistory(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.PoolHistory{}
+	result := response.PoolHistory{}
+
+	errCode := validate.NewPoolHistory().PoolHistory(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewPoolHistory().PoolHistory(&req, &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
 func (c *PoolController) TokenList(ctx *gin.Context) {
 
 	req := request.TokenList{}
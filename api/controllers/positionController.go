@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models/request"
+	"pledge-backend/api/models/response"
+	"pledge-backend/api/services"
+	"pledge-backend/api/validate"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PositionController struct {
+}
+
+func (c *PositionController) Positions(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.Positions{}
+	result := response.UserPositions{}
+
+	errCode := validate.NewPosition().Positions(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewPosition().UserPositions(&req, &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
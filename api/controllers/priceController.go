@@ -4,7 +4,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"net/http"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models/request"
+	"pledge-backend/api/models/response"
 	"pledge-backend/api/models/ws"
+	"pledge-backend/api/services"
+	"pledge-backend/api/validate"
 	"pledge-backend/log"
 	"pledge-backend/utils"
 	"strings"
@@ -53,3 +58,40 @@ func (c *PriceController) NewPrice(ctx *gin.Context) {
 
 	go server.ReadAndWrite()
 }
+
+// PriceStatus Last plgr price, its staleness and the ku-coin connection state of the producer
+func (c *PriceController) PriceStatus(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	result := response.PriceStatus{}
+
+	errCode := services.NewPrice().PriceStatus(&result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+// PriceHistory OHLC candles of a token price
+func (c *PriceController) PriceHistory(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.PriceHistory{}
+	result := response.PriceHistory{}
+
+	errCode := validate.NewPriceHistory().PriceHistory(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewPrice().PriceHistory(&req, &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
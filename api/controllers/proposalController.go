@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
+	"pledge-backend/api/models/request"
+	"pledge-backend/api/models/response"
+	"pledge-backend/api/services"
+	"pledge-backend/api/validate"
+)
+
+type ProposalController struct {
+}
+
+func (c *ProposalController) CreateProposal(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.CreateProposal{}
+	result := models.Proposal{}
+
+	errCode := validate.NewProposal().CreateProposal(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewProposal().CreateProposal(&req, ctx.GetString("username"), &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *ProposalController) ProposalList(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.ProposalList{}
+	var result []models.Proposal
+
+	errCode := validate.NewProposal().ProposalList(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewProposal().ProposalList(&req, &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *ProposalController) ProposalDetail(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.ProposalId{}
+	result := response.ProposalDetail{}
+
+	errCode := validate.NewProposal().ProposalId(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewProposal().ProposalDetail(&req, &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *ProposalController) SignProposal(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.SignProposal{}
+	result := response.ProposalSign{}
+
+	errCode := validate.NewProposal().SignProposal(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewProposal().SignProposal(&req, ctx.GetString("username"), &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *ProposalController) ProposalPayload(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.ProposalId{}
+	result := response.ProposalPayload{}
+
+	errCode := validate.NewProposal().ProposalId(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewProposal().ProposalPayload(&req, &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *ProposalController) ExecuteProposal(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.ExecuteProposal{}
+
+	errCode := validate.NewProposal().ExecuteProposal(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewProposal().ExecuteProposal(&req, ctx.GetString("username"))
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, nil)
+	return
+}
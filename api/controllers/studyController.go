@@ -98,3 +98,16 @@ func (c *StudyController) SetItem(ctx *gin.Context) {
 	}
 	response.Response(ctx, statecode.CommonSuccess, res)
 }
+
+// BackfillStatus progress of the block backfill
+func (c *StudyController) BackfillStatus(ctx *gin.Context) {
+	result := response.BackfillStatus{}
+	response := response.Gin{Res: ctx}
+
+	returnCode := services.NewEthService().BackfillStatus(&result)
+	if statecode.CommonSuccess != returnCode {
+		response.Response(ctx, returnCode, nil)
+		return
+	}
+	response.Response(ctx, statecode.CommonSuccess, result)
+}
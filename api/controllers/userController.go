@@ -3,11 +3,11 @@ package controllers
 import (
 	"github.com/gin-gonic/gin"
 	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
 	"pledge-backend/api/models/request"
 	"pledge-backend/api/models/response"
 	"pledge-backend/api/services"
 	"pledge-backend/api/validate"
-	"pledge-backend/db"
 )
 
 type UserController struct {
@@ -24,7 +24,63 @@ func (c *UserController) Login(ctx *gin.Context) {
 		return
 	}
 
-	errCode = services.NewUser().Login(&req, &result)
+	errCode = services.NewUser().Login(&req, ctx.ClientIP(), ctx.Request.UserAgent(), &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *UserController) Nonce(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	result := response.Nonce{}
+
+	errCode := services.NewUser().Nonce(&result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *UserController) SiweLogin(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.SiweLogin{}
+	result := response.Login{}
+
+	errCode := validate.NewUser().SiweLogin(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewUser().SiweLogin(&req, ctx.ClientIP(), ctx.Request.UserAgent(), &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *UserController) Refresh(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.RefreshToken{}
+	result := response.Login{}
+
+	errCode := validate.NewUser().RefreshToken(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewUser().Refresh(&req, &result)
 	if errCode != statecode.CommonSuccess {
 		res.Response(ctx, errCode, nil)
 		return
@@ -37,11 +93,155 @@ func (c *UserController) Login(ctx *gin.Context) {
 func (c *UserController) Logout(ctx *gin.Context) {
 	res := response.Gin{Res: ctx}
 
-	usernameIntf, _ := ctx.Get("username")
+	errCode := services.NewUser().Logout(ctx.GetString("session_id"))
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, nil)
+	return
+}
+
+func (c *UserController) LogoutAll(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
 
-	//delete username in redis
-	_, _ = db.RedisDelete(usernameIntf.(string))
+	errCode := services.NewUser().LogoutAll(ctx.GetString("username"))
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
 
 	res.Response(ctx, statecode.CommonSuccess, nil)
 	return
 }
+
+func (c *UserController) Sessions(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	var result []models.AdminSession
+
+	errCode := services.NewUser().Sessions(ctx.GetString("username"), ctx.GetString("session_id"), &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *UserController) RevokeSession(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.RevokeSession{}
+
+	errCode := validate.NewUser().RevokeSession(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewUser().RevokeSession(&req, ctx.GetString("username"))
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, nil)
+	return
+}
+
+func (c *UserController) AdminUsers(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	var result []models.AdminUser
+
+	errCode := services.NewUser().AdminUsers(&result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *UserController) CreateAdminUser(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.CreateAdminUser{}
+	result := models.AdminUser{}
+
+	errCode := validate.NewUser().CreateAdminUser(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewUser().CreateAdminUser(&req, &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
+
+func (c *UserController) UpdateAdminUser(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.UpdateAdminUser{}
+
+	errCode := validate.NewUser().UpdateAdminUser(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewUser().UpdateAdminUser(&req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, nil)
+	return
+}
+
+func (c *UserController) DeleteAdminUser(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.DeleteAdminUser{}
+
+	errCode := validate.NewUser().DeleteAdminUser(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewUser().DeleteAdminUser(&req, ctx.GetString("username"))
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, nil)
+	return
+}
+
+func (c *UserController) AdminUserSessions(ctx *gin.Context) {
+	res := response.Gin{Res: ctx}
+	req := request.AdminUserSessions{}
+	var result []models.AdminSession
+
+	errCode := validate.NewUser().AdminUserSessions(ctx, &req)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	errCode = services.NewUser().AdminUserSessions(&req, &result)
+	if errCode != statecode.CommonSuccess {
+		res.Response(ctx, errCode, nil)
+		return
+	}
+
+	res.Response(ctx, statecode.CommonSuccess, result)
+	return
+}
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	consts "pledge-backend/api/common"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models/response"
+)
+
+// CheckRole Require at least the given role, use it after CheckToken
+func CheckRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res := response.Gin{Res: c}
+
+		userRole := c.GetString("role")
+		if consts.ADMIN_ROLE_RANK[userRole] < consts.ADMIN_ROLE_RANK[role] {
+			res.Response(c, statecode.PermissionDenied, nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -3,9 +3,9 @@ package middlewares
 import (
 	"github.com/gin-gonic/gin"
 	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
 	"pledge-backend/api/models/response"
 	"pledge-backend/config"
-	"pledge-backend/db"
 	"pledge-backend/utils"
 )
 
@@ -14,28 +14,39 @@ func CheckToken() gin.HandlerFunc {
 		res := response.Gin{Res: c}
 		token := c.Request.Header.Get("authCode")
 
-		username, err := utils.ParseToken(token, config.Config.Jwt.SecretKey)
+		claims, err := utils.ParseToken(token, config.Config.Jwt.SecretKey, utils.TokenTypeAccess)
 		if err != nil {
 			res.Response(c, statecode.TokenErr, nil)
 			c.Abort()
 			return
 		}
 
-		if username != config.Config.DefaultAdmin.Username {
+		// a logged out or rotated access token is on the revocation list until it expires
+		if models.NewAdminSession().IsTokenRevoked(claims.Id) {
 			res.Response(c, statecode.TokenErr, nil)
 			c.Abort()
 			return
 		}
 
-		// Judge whether the user logout
-		resByteArr, err := db.RedisGet(username)
-		if string(resByteArr) != `"login_ok"` {
+		// Judge whether the session is still active
+		session, err := models.NewAdminSession().GetSession(claims.Sid)
+		if err != nil || session.Username != claims.Username {
 			res.Response(c, statecode.TokenErr, nil)
 			c.Abort()
 			return
 		}
 
-		c.Set("username", username)
+		// the role is read on every request so a changed or removed user takes effect at once
+		adminUser, err := models.NewAdminUser().GetByUsername(claims.Username)
+		if err != nil {
+			res.Response(c, statecode.TokenErr, nil)
+			c.Abort()
+			return
+		}
+
+		c.Set("username", claims.Username)
+		c.Set("role", adminUser.Role)
+		c.Set("session_id", claims.Sid)
 
 		c.Next()
 	}
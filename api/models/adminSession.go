@@ -0,0 +1,147 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	consts "pledge-backend/api/common"
+	"pledge-backend/db"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrSessionNotFound the session expired or was revoked
+var ErrSessionNotFound = errors.New("session not found")
+
+// AdminSession login session of an admin user, kept in redis until its refresh token expires or it is revoked
+type AdminSession struct {
+	Id             string `json:"id"`
+	Username       string `json:"username"`
+	ClientIp       string `json:"client_ip"`
+	UserAgent      string `json:"user_agent"`
+	AccessJti      string `json:"-"` // jti of the latest access token, revoked with the session
+	AccessExpireAt int64  `json:"-"`
+	CreatedAt      string `json:"created_at"`
+	RefreshedAt    string `json:"refreshed_at"`
+	ExpireAt       int64  `json:"expire_at"` // unix seconds
+	Current        bool   `json:"current"`   // the session of the request, set when listing
+}
+
+// sessionStore redis record of a session, AdminSession hides the token ids from the api output
+type sessionStore struct {
+	AdminSession
+	AccessJti      string `json:"access_jti"`
+	AccessExpireAt int64  `json:"access_expire_at"`
+}
+
+func NewAdminSession() *AdminSession {
+	return &AdminSession{}
+}
+
+// SaveSession Save a session and the refresh token jti that can rotate it
+func (a *AdminSession) SaveSession(session *AdminSession, refreshJti string) error {
+	ttl := int(session.ExpireAt - time.Now().Unix())
+	if ttl <= 0 {
+		return errors.New("session already expired")
+	}
+	err := db.RedisSet(consts.ADMIN_SESSION_KEY_PREFIX+session.Id, sessionStore{
+		AdminSession:   *session,
+		AccessJti:      session.AccessJti,
+		AccessExpireAt: session.AccessExpireAt,
+	}, ttl)
+	if err != nil {
+		return err
+	}
+	if db.RedisSAdd(consts.ADMIN_SESSIONS_KEY_PREFIX+session.Username, session.Id) < 0 {
+		return errors.New("session index err")
+	}
+	return db.RedisSetString(consts.ADMIN_REFRESH_KEY_PREFIX+refreshJti, session.Id, ttl)
+}
+
+// GetSession Get a session, ErrSessionNotFound if it expired or was revoked
+func (a *AdminSession) GetSession(id string) (*AdminSession, error) {
+	sessionBytes, err := db.RedisGet(consts.ADMIN_SESSION_KEY_PREFIX + id)
+	if err != nil {
+		if errors.Is(err, redis.ErrNil) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	store := sessionStore{}
+	err = json.Unmarshal(sessionBytes, &store)
+	if err != nil {
+		return nil, err
+	}
+	session := store.AdminSession
+	session.AccessJti, session.AccessExpireAt = store.AccessJti, store.AccessExpireAt
+	return &session, nil
+}
+
+// ListSessions active sessions of a username, expired ones are dropped from the index
+func (a *AdminSession) ListSessions(username string) ([]AdminSession, error) {
+	ids, err := db.RedisSmembers(consts.ADMIN_SESSIONS_KEY_PREFIX + username)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]AdminSession, 0, len(ids))
+	for _, id := range ids {
+		session, err := a.GetSession(id)
+		if errors.Is(err, ErrSessionNotFound) {
+			_ = db.RedisSRem(consts.ADMIN_SESSIONS_KEY_PREFIX+username, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, nil
+}
+
+// UseRefreshToken Consume a refresh token jti, false if it was already used or expired.
+// The delete is atomic so a refresh token rotates the session only once.
+func (a *AdminSession) UseRefreshToken(jti string) (bool, error) {
+	return db.RedisDelete(consts.ADMIN_REFRESH_KEY_PREFIX + jti)
+}
+
+// RevokeToken Put an access token jti on the revocation list until the token expires
+func (a *AdminSession) RevokeToken(jti string, expireAt int64) error {
+	ttl := int(expireAt - time.Now().Unix())
+	if jti == "" || ttl <= 0 {
+		return nil
+	}
+	return db.RedisSetString(consts.ADMIN_REVOKED_KEY_PREFIX+jti, "1", ttl)
+}
+
+// IsTokenRevoked whether an access token jti is on the revocation list
+func (a *AdminSession) IsTokenRevoked(jti string) bool {
+	return db.RedisExists(consts.ADMIN_REVOKED_KEY_PREFIX + jti)
+}
+
+// RevokeSession Delete a session and revoke its latest access token, its refresh token can not be used anymore
+func (a *AdminSession) RevokeSession(session *AdminSession) error {
+	err := a.RevokeToken(session.AccessJti, session.AccessExpireAt)
+	if err != nil {
+		return err
+	}
+	_, err = db.RedisDelete(consts.ADMIN_SESSION_KEY_PREFIX + session.Id)
+	if err != nil {
+		return err
+	}
+	return db.RedisSRem(consts.ADMIN_SESSIONS_KEY_PREFIX+session.Username, session.Id)
+}
+
+// RevokeAllSessions Log out every session of a username
+func (a *AdminSession) RevokeAllSessions(username string) error {
+	sessions, err := a.ListSessions(username)
+	if err != nil {
+		return err
+	}
+	for i := range sessions {
+		err = a.RevokeSession(&sessions[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	consts "pledge-backend/api/common"
+	"pledge-backend/config"
+	"pledge-backend/db"
+	"pledge-backend/log"
+	"pledge-backend/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AdminUser backend account, the password is stored as a bcrypt hash
+type AdminUser struct {
+	Id           int    `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Username     string `json:"username" gorm:"column:username;type:varchar(64);uniqueIndex"`
+	PasswordHash string `json:"-" gorm:"column:password_hash;type:varchar(100)"`
+	Role         string `json:"role" gorm:"column:role;type:varchar(20)"`
+	CreatedAt    string `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt    string `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func NewAdminUser() *AdminUser {
+	return &AdminUser{}
+}
+
+func (a *AdminUser) TableName() string {
+	return "admin_users"
+}
+
+// GetByUsername Get an admin user, gorm.ErrRecordNotFound if there is none
+func (a *AdminUser) GetByUsername(username string) (AdminUser, error) {
+	adminUser := AdminUser{}
+	err := db.Mysql.Table("admin_users").Where("username=?", username).First(&adminUser).Debug().Error
+	return adminUser, err
+}
+
+// GetById Get an admin user, gorm.ErrRecordNotFound if there is none
+func (a *AdminUser) GetById(id int) (AdminUser, error) {
+	adminUser := AdminUser{}
+	err := db.Mysql.Table("admin_users").Where("id=?", id).First(&adminUser).Debug().Error
+	return adminUser, err
+}
+
+// List all admin users
+func (a *AdminUser) List() ([]AdminUser, error) {
+	adminUsers := make([]AdminUser, 0)
+	err := db.Mysql.Table("admin_users").Order("id asc").Find(&adminUsers).Debug().Error
+	if err != nil {
+		return nil, err
+	}
+	return adminUsers, nil
+}
+
+// CountByRole number of admin users with a role
+func (a *AdminUser) CountByRole(role string) (int64, error) {
+	var count int64
+	err := db.Mysql.Table("admin_users").Where("role=?", role).Count(&count).Debug().Error
+	return count, err
+}
+
+// Create an admin user with the hash of the password
+func (a *AdminUser) Create(username, password, role string) (AdminUser, error) {
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return AdminUser{}, err
+	}
+	nowDateTime := utils.GetCurDateTimeFormat()
+	adminUser := AdminUser{
+		Username:     username,
+		PasswordHash: passwordHash,
+		Role:         role,
+		CreatedAt:    nowDateTime,
+		UpdatedAt:    nowDateTime,
+	}
+	err = db.Mysql.Table("admin_users").Create(&adminUser).Debug().Error
+	return adminUser, err
+}
+
+// CreateWalletUser Create the admin user of a wallet login, it has no password so it can not use /user/login
+func (a *AdminUser) CreateWalletUser(address, role string) (AdminUser, error) {
+	nowDateTime := utils.GetCurDateTimeFormat()
+	adminUser := AdminUser{
+		Username:  address,
+		Role:      role,
+		CreatedAt: nowDateTime,
+		UpdatedAt: nowDateTime,
+	}
+	err := db.Mysql.Table("admin_users").Create(&adminUser).Debug().Error
+	return adminUser, err
+}
+
+// IsWalletUser whether the user was created by a wallet login, its access depends on the multi-sign accounts
+func (a *AdminUser) IsWalletUser() bool {
+	return a.PasswordHash == "" && common.IsHexAddress(a.Username)
+}
+
+// Update the role and, if it is not empty, the password of an admin user
+func (a *AdminUser) Update(id int, password, role string) error {
+	values := map[string]interface{}{
+		"role":       role,
+		"updated_at": utils.GetCurDateTimeFormat(),
+	}
+	if password != "" {
+		passwordHash, err := utils.HashPassword(password)
+		if err != nil {
+			return err
+		}
+		values["password_hash"] = passwordHash
+	}
+	return db.Mysql.Table("admin_users").Where("id=?", id).Updates(values).Debug().Error
+}
+
+// Delete an admin user
+func (a *AdminUser) Delete(id int) error {
+	return db.Mysql.Table("admin_users").Where("id=?", id).Delete(&AdminUser{}).Debug().Error
+}
+
+// SeedDefaultAdmin Create the [defaultadmin] account as multisig-admin when the table is empty
+func (a *AdminUser) SeedDefaultAdmin() {
+	var count int64
+	err := db.Mysql.Table("admin_users").Count(&count).Debug().Error
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return
+	}
+	if count > 0 {
+		return
+	}
+	_, err = a.Create(config.Config.DefaultAdmin.Username, config.Config.DefaultAdmin.Password, consts.ADMIN_ROLE_MULTISIG_ADMIN)
+	if err != nil {
+		log.Logger.Error(err.Error())
+	}
+}
@@ -1,14 +1,44 @@
 package models
 
+import (
+	"pledge-backend/schedule/models"
+)
+
 type Block struct {
 	Id           uint64 `json:"-" gorm:"primary_key;AUTO_INCREMENT"`
-	Hash         string `json:"hash" gorm:"column:hash"`
-	Number       uint64 `json:"number" gorm:"column:number"`
+	Hash         string `json:"hash" gorm:"column:hash;type:varchar(66);index"`
+	ParentHash   string `json:"parentHash" gorm:"column:parent_hash;type:varchar(66)"`
+	Number       uint64 `json:"number" gorm:"column:number;index"`
 	Time         uint64 `json:"time" gorm:"column:time"`
 	Nonce        uint64 `json:"nonce" gorm:"column:nonce"`
 	Transactions uint64 `json:"transactions" gorm:"column:transactions"`
+	Canonical    bool   `json:"canonical" gorm:"column:canonical;default:true"` // false once the block was reorged out
+}
+
+func NewBlock() *Block {
+	return &Block{}
 }
 
 func (b *Block) TableName() string {
 	return "block"
 }
+
+// Save Save a block read from the chain as the canonical block of its number, the other cached blocks of the number are orphaned
+func (b *Block) Save(block *Block) error {
+	cached := models.Block{
+		Hash:         block.Hash,
+		ParentHash:   block.ParentHash,
+		Nonce:        block.Nonce,
+		Number:       block.Number,
+		Time:         block.Time,
+		Transactions: block.Transactions,
+	}
+	err := cached.Save()
+	block.Id, block.Canonical = cached.Id, cached.Canonical
+	return err
+}
+
+// MarkOrphaned Mark a reorged block with its transactions and receipts as non-canonical
+func (b *Block) MarkOrphaned(hash string) error {
+	return (&models.Block{}).MarkOrphaned(hash)
+}
@@ -0,0 +1,30 @@
+package models
+
+import "pledge-backend/db"
+
+// BlockBackfill progress of the backfill of a block range, written by the schedule
+type BlockBackfill struct {
+	Id          int    `json:"-" gorm:"column:id;primaryKey"`
+	StartBlock  uint64 `json:"start_block" gorm:"column:start_block"`
+	EndBlock    uint64 `json:"end_block" gorm:"column:end_block"`
+	NextBlock   uint64 `json:"next_block" gorm:"column:next_block"`
+	TargetBlock uint64 `json:"target_block" gorm:"column:target_block"`
+	Status      string `json:"status" gorm:"column:status"`
+	Error       string `json:"error" gorm:"column:error"`
+	UpdatedAt   string `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func NewBlockBackfill() *BlockBackfill {
+	return &BlockBackfill{}
+}
+
+func (b *BlockBackfill) TableName() string {
+	return "block_backfill"
+}
+
+// GetBackfill Get the progress of a block range, gorm.ErrRecordNotFound if it was never backfilled
+func (b *BlockBackfill) GetBackfill(startBlock, endBlock uint64) (BlockBackfill, error) {
+	backfill := BlockBackfill{}
+	err := db.Mysql.Table("block_backfill").Where("start_block=? and end_block=?", startBlock, endBlock).First(&backfill).Debug().Error
+	return backfill, err
+}
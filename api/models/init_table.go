@@ -11,4 +11,10 @@ func InitTable() {
 	db.Mysql.AutoMigrate(&Transaction{})
 	db.Mysql.AutoMigrate(&Receipt{})
 	db.Mysql.AutoMigrate(&Block{})
+	db.Mysql.AutoMigrate(&AdminUser{})
+	db.Mysql.AutoMigrate(&Proposal{})
+	db.Mysql.AutoMigrate(&ProposalSignature{})
+
+	// the first start has no admin user yet
+	NewAdminUser().SeedDefaultAdmin()
 }
@@ -1,29 +1,161 @@
 package kucoin
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/Kucoin/kucoin-go-sdk"
+	"pledge-backend/config"
 	"pledge-backend/db"
 	"pledge-backend/log"
+	"pledge-backend/pubsub"
+	"pledge-backend/utils"
+	"strconv"
+	"time"
 )
 
 // ApiKeyVersionV2 is v2 api key version
 const ApiKeyVersionV2 = "2"
 
+// the instance that holds the lock is the only one connected to ku-coin, it publishes the price to every api instance
+const (
+	ProducerLockKey = "lock:plgr_price_producer"
+	ProducerLockTtl = 30 // seconds, renewed every third of it
+	ProducerRetry   = 10 * time.Second
+)
+
+// redis keys shared with the schedule and the other api instances
+const (
+	PriceKey          = "plgr_price"
+	PriceUpdatedAtKey = "plgr_price_updated_at" // unix seconds of the last ticker message
+	ProducerStatusKey = "plgr_price_producer_status"
+)
+
+// producer connection states
+const (
+	StateConnecting = "connecting"
+	StateConnected  = "connected"
+	StateBackoff    = "backoff"
+)
+
+// ProducerStatus connection state of the elected producer, it expires when the producer stops reporting
+type ProducerStatus struct {
+	InstanceId  string `json:"instance_id"`
+	State       string `json:"state"`
+	ConnectedAt int64  `json:"connected_at"`
+	LastTickAt  int64  `json:"last_tick_at"`
+	Reconnects  int    `json:"reconnects"`
+	LastError   string `json:"last_error"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
 var PlgrPrice = "0.0027"
-var PlgrPriceChan = make(chan string, 2)
 
+var errLockLost = errors.New("producer lock lost")
+
+// GetExchangePrice Take the producer lock when it is free and stream the price, the other instances keep waiting to take over
 func GetExchangePrice() {
 
 	log.Logger.Sugar().Info("GetExchangePrice ")
 
 	// get plgr price from redis
-	price, err := db.RedisGetString("plgr_price")
+	price, err := db.RedisGetString(PriceKey)
 	if err != nil {
 		log.Logger.Sugar().Error("get plgr price from redis err ", err)
 	} else {
 		PlgrPrice = price
 	}
 
+	instanceId := utils.UniqueId()
+	for {
+		locked, err := db.RedisTryLock(ProducerLockKey, instanceId, ProducerLockTtl)
+		if err != nil {
+			log.Logger.Sugar().Error("plgr price producer lock err ", err)
+		}
+		if locked {
+			log.Logger.Sugar().Info("plgr price producer elected ", instanceId)
+			producePrice(instanceId)
+			_ = db.RedisReleaseLock(ProducerLockKey, instanceId)
+		}
+		time.Sleep(ProducerRetry)
+	}
+}
+
+// GetProducerStatus Status reported by the elected producer, nil if no producer reported recently
+func GetProducerStatus() *ProducerStatus {
+	data, err := db.RedisGet(ProducerStatusKey)
+	if err != nil {
+		return nil
+	}
+	status := &ProducerStatus{}
+	if err := json.Unmarshal(data, status); err != nil {
+		return nil
+	}
+	return status
+}
+
+// GetPriceUpdatedAt Unix seconds of the last stored price, 0 if it was never stored
+func GetPriceUpdatedAt() int64 {
+	updatedAt, err := db.RedisGetInt64(PriceUpdatedAtKey)
+	if err != nil {
+		return 0
+	}
+	return updatedAt
+}
+
+// IsPriceStale Whether the stored price is older than the kucoin stale_threshold
+func IsPriceStale(updatedAt int64) bool {
+	return updatedAt == 0 || time.Now().Unix()-updatedAt > config.Config.Kucoin.StaleThreshold
+}
+
+// producePrice Keep a ku-coin ticker stream open while the lock is held, a failed stream is reconnected with a fresh token
+// after an exponential backoff, which starts over once a tick was received
+func producePrice(instanceId string) {
+
+	status := &ProducerStatus{InstanceId: instanceId}
+	backoffMin := time.Duration(config.Config.Kucoin.ReconnectBackoffMin) * time.Second
+	backoffMax := time.Duration(config.Config.Kucoin.ReconnectBackoffMax) * time.Second
+	if backoffMin <= 0 {
+		backoffMin = time.Second
+	}
+	if backoffMax < backoffMin {
+		backoffMax = backoffMin
+	}
+	backoff := backoffMin
+
+	for {
+		status.State = StateConnecting
+		saveStatus(status)
+
+		ticked, err := streamPrice(instanceId, status)
+		if errors.Is(err, errLockLost) {
+			log.Logger.Sugar().Error("plgr price producer lost the lock ", instanceId)
+			_, _ = db.RedisDelete(ProducerStatusKey)
+			return
+		}
+		if ticked {
+			backoff = backoffMin
+		}
+		log.Logger.Sugar().Error("plgr price stream err, reconnect in ", backoff, " ", err)
+		status.State = StateBackoff
+		status.Reconnects++
+		status.LastError = err.Error()
+		saveStatus(status)
+
+		if !sleepHoldingLock(instanceId, backoff) {
+			log.Logger.Sugar().Error("plgr price producer lost the lock ", instanceId)
+			_, _ = db.RedisDelete(ProducerStatusKey)
+			return
+		}
+		backoff *= 2
+		if backoff > backoffMax {
+			backoff = backoffMax
+		}
+	}
+}
+
+// streamPrice Stream the ticker until the socket fails or the lock is lost, ticked reports whether a price was received
+func streamPrice(instanceId string, status *ProducerStatus) (ticked bool, err error) {
+
 	s := kucoin.NewApiService(
 		kucoin.ApiKeyOption("key"),
 		kucoin.ApiSecretOption("secret"),
@@ -31,51 +163,92 @@ func GetExchangePrice() {
 		kucoin.ApiKeyVersionOption(ApiKeyVersionV2),
 	)
 
+	// the token is only valid for one connection, every reconnect asks for a new one
 	rsp, err := s.WebSocketPublicToken()
 	if err != nil {
-		log.Logger.Error(err.Error()) // Handle error
-		return
+		return false, err
 	}
 
 	tk := &kucoin.WebSocketTokenModel{}
 	if err := rsp.ReadData(tk); err != nil {
-		log.Logger.Error(err.Error())
-		return
+		return false, err
 	}
 
 	c := s.NewWebSocketClient(tk)
 
 	mc, ec, err := c.Connect()
 	if err != nil {
-		log.Logger.Sugar().Errorf("Error: %s", err.Error())
-		return
+		return false, err
 	}
+	defer c.Stop() // Stop subscribing the WebSocket feed
 
-	ch := kucoin.NewSubscribeMessage("/market/ticker:PLGR-USDT", false)
-	uch := kucoin.NewUnsubscribeMessage("/market/ticker:PLGR-USDT", false)
+	ch := kucoin.NewSubscribeMessage("/market/ticker:"+pubsub.MarketPriceSymbol, false)
+	uch := kucoin.NewUnsubscribeMessage("/market/ticker:"+pubsub.MarketPriceSymbol, false)
 
 	if err := c.Subscribe(ch); err != nil {
-		log.Logger.Error(err.Error()) // Handle error
-		return
+		return false, err
 	}
 
+	status.State = StateConnected
+	status.ConnectedAt = time.Now().Unix()
+	saveStatus(status)
+
+	renew := time.NewTicker(ProducerLockTtl / 3 * time.Second)
+	defer renew.Stop()
+
 	for {
 		select {
+		case <-renew.C:
+			ok, err := db.RedisRenewLock(ProducerLockKey, instanceId, ProducerLockTtl)
+			if err != nil || !ok {
+				_ = c.Unsubscribe(uch)
+				return ticked, errLockLost
+			}
+			saveStatus(status)
 		case err := <-ec:
-			c.Stop() // Stop subscribing the WebSocket feed
-			log.Logger.Sugar().Errorf("Error: %s", err.Error())
 			_ = c.Unsubscribe(uch)
-			return
+			return ticked, err
 		case msg := <-mc:
 			t := &kucoin.TickerLevel1Model{}
 			if err := msg.ReadData(t); err != nil {
 				log.Logger.Sugar().Errorf("Failure to read: %s", err.Error())
-				return
+				continue
 			}
-			PlgrPriceChan <- t.Price
+			now := time.Now().Unix()
 			PlgrPrice = t.Price
-			//log.Logger.Sugar().Info("Price ", t.Price)
-			_ = db.RedisSetString("plgr_price", PlgrPrice, 0)
+			_ = db.RedisSetString(PriceKey, PlgrPrice, 0)
+			_ = db.RedisSetString(PriceUpdatedAtKey, strconv.FormatInt(now, 10), 0)
+			_ = pubsub.Publish(pubsub.MarketPriceTopic(pubsub.MarketPriceSymbol), t.Price)
+			ticked = true
+			status.LastTickAt = now
+		}
+	}
+}
+
+// sleepHoldingLock Wait for d while renewing the lock, false if the lock was lost
+func sleepHoldingLock(instanceId string, d time.Duration) bool {
+	deadline := time.Now().Add(d)
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return true
+		}
+		if remaining > ProducerLockTtl/3*time.Second {
+			remaining = ProducerLockTtl / 3 * time.Second
 		}
+		time.Sleep(remaining)
+		ok, err := db.RedisRenewLock(ProducerLockKey, instanceId, ProducerLockTtl)
+		if err != nil || !ok {
+			return false
+		}
+	}
+}
+
+// saveStatus Report the producer status, it expires if the producer dies without cleaning it
+func saveStatus(status *ProducerStatus) {
+	status.UpdatedAt = time.Now().Unix()
+	err := db.RedisSet(ProducerStatusKey, status, ProducerLockTtl*2)
+	if err != nil {
+		log.Logger.Sugar().Error("save plgr price producer status err ", err)
 	}
 }
@@ -6,13 +6,15 @@ import (
 	"gorm.io/gorm"
 	"pledge-backend/api/models/request"
 	"pledge-backend/db"
+	"pledge-backend/utils"
+	"strings"
 )
 
-// MultiSign multi-sign signature
+// MultiSign multi-sign signature, every change is kept as a new version and one version per chain is active
 type MultiSign struct {
 	Id               int32  `gorm:"column:id;primaryKey"`
 	SpName           string `json:"sp_name" gorm:"column:sp_name"`
-	ChainId          int    `json:"chain_id" gorm:"column:chain_id"`
+	ChainId          int    `json:"chain_id" gorm:"column:chain_id;uniqueIndex:uk_chain_version"`
 	SpToken          string `json:"_spToken" gorm:"column:sp_token"`
 	JpName           string `json:"jp_name" gorm:"column:jp_name"`
 	JpToken          string `json:"_jpToken" gorm:"column:jp_token"`
@@ -21,21 +23,22 @@ type MultiSign struct {
 	SpHash           string `json:"spHash" gorm:"column:sp_hash"`
 	JpHash           string `json:"jpHash" gorm:"column:jp_hash"`
 	MultiSignAccount string `json:"multi_sign_account" gorm:"column:multi_sign_account"`
+	Version          int    `json:"version" gorm:"column:version;default:1;uniqueIndex:uk_chain_version"`
+	IsActive         bool   `json:"is_active" gorm:"column:is_active;default:true"`
+	RollbackFrom     int    `json:"rollback_from" gorm:"column:rollback_from"` // version restored by a rollback, 0 for a set
+	CreatedBy        string `json:"created_by" gorm:"column:created_by;type:varchar(64)"`
+	CreatedAt        string `json:"created_at" gorm:"column:created_at"`
 }
 
 func NewMultiSign() *MultiSign {
 	return &MultiSign{}
 }
 
-// Set Multi-Sign
-func (m *MultiSign) Set(multiSign *request.SetMultiSign) error {
+// Set Multi-Sign, the new version becomes the active one and the previous versions are kept
+func (m *MultiSign) Set(multiSign *request.SetMultiSign, operator string) error {
 
 	MultiSignAccountByteArr, _ := json.Marshal(multiSign.MultiSignAccount)
-	err := db.Mysql.Table("multi_sign").Where("chain_id", multiSign.ChainId).Delete(&m).Debug().Error
-	if err != nil {
-		return errors.New("record select err " + err.Error())
-	}
-	err = db.Mysql.Table("multi_sign").Where("id=?", m.Id).Create(&MultiSign{
+	return m.addVersion(&MultiSign{
 		ChainId:          multiSign.ChainId,
 		SpName:           multiSign.SpName,
 		SpToken:          multiSign.SpToken,
@@ -46,16 +49,47 @@ func (m *MultiSign) Set(multiSign *request.SetMultiSign) error {
 		SpHash:           multiSign.SpHash,
 		JpHash:           multiSign.JpHash,
 		MultiSignAccount: string(MultiSignAccountByteArr),
-	}).Debug().Error
+		CreatedBy:        operator,
+	})
+}
+
+// Rollback Re-activate a prior version, it is copied as a new version so the history stays append only
+func (m *MultiSign) Rollback(chainId, version int, operator string) (MultiSign, error) {
+	target, err := m.GetVersion(chainId, version)
 	if err != nil {
-		return err
+		return target, err
 	}
-	return nil
+	multiSign := target
+	multiSign.Id = 0
+	multiSign.RollbackFrom = version
+	multiSign.CreatedBy = operator
+	err = m.addVersion(&multiSign)
+	return multiSign, err
+}
+
+// addVersion Insert the next version of a chain and deactivate the others
+func (m *MultiSign) addVersion(multiSign *MultiSign) error {
+	return db.Mysql.Transaction(func(tx *gorm.DB) error {
+		var lastVersion int
+		err := tx.Table("multi_sign").Where("chain_id=?", multiSign.ChainId).Select("COALESCE(MAX(version), 0)").Scan(&lastVersion).Debug().Error
+		if err != nil {
+			return errors.New("record select err " + err.Error())
+		}
+		err = tx.Table("multi_sign").Where("chain_id=?", multiSign.ChainId).Update("is_active", false).Debug().Error
+		if err != nil {
+			return err
+		}
+		multiSign.Version = lastVersion + 1
+		multiSign.IsActive = true
+		multiSign.CreatedAt = utils.GetCurDateTimeFormat()
+		// the unique key on chain_id and version rejects a concurrent set of the same version
+		return tx.Table("multi_sign").Create(multiSign).Debug().Error
+	})
 }
 
 // Get Multi-Sign
 func (m *MultiSign) Get(chainId int) error {
-	err := db.Mysql.Table("multi_sign").Where("chain_id", chainId).First(&m).Debug().Error
+	err := db.Mysql.Table("multi_sign").Where("chain_id=? and is_active=?", chainId, true).First(&m).Debug().Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil
@@ -65,3 +99,50 @@ func (m *MultiSign) Get(chainId int) error {
 	}
 	return nil
 }
+
+// IsMultiSignAccount whether the address is in the multi_sign_account list of a chain
+func (m *MultiSign) IsMultiSignAccount(chainId int, address string) (bool, error) {
+	accounts, err := m.Accounts(chainId)
+	if err != nil {
+		return false, err
+	}
+	for _, account := range accounts {
+		if strings.EqualFold(account, address) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Accounts the multi_sign_account list of the active version of a chain, empty if the chain has none
+func (m *MultiSign) Accounts(chainId int) ([]string, error) {
+	multiSign := MultiSign{}
+	err := db.Mysql.Table("multi_sign").Where("chain_id=? and is_active=?", chainId, true).First(&multiSign).Debug().Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.New("record select err " + err.Error())
+	}
+
+	var accounts []string
+	_ = json.Unmarshal([]byte(multiSign.MultiSignAccount), &accounts)
+	return accounts, nil
+}
+
+// GetVersion Get one version of a chain, gorm.ErrRecordNotFound if there is none
+func (m *MultiSign) GetVersion(chainId, version int) (MultiSign, error) {
+	multiSign := MultiSign{}
+	err := db.Mysql.Table("multi_sign").Where("chain_id=? and version=?", chainId, version).First(&multiSign).Debug().Error
+	return multiSign, err
+}
+
+// History every version of a chain, newest first
+func (m *MultiSign) History(chainId int) ([]MultiSign, error) {
+	multiSigns := make([]MultiSign, 0)
+	err := db.Mysql.Table("multi_sign").Where("chain_id=?", chainId).Order("version desc").Find(&multiSigns).Debug().Error
+	if err != nil {
+		return nil, errors.New("record select err " + err.Error())
+	}
+	return multiSigns, nil
+}
@@ -8,23 +8,26 @@ import (
 )
 
 type Pool struct {
-	PoolID                 int      `json:"pool_id"`
-	SettleTime             string   `json:"settleTime"`
-	EndTime                string   `json:"endTime"`
-	InterestRate           string   `json:"interestRate"`
-	MaxSupply              string   `json:"maxSupply"`
-	LendSupply             string   `json:"lendSupply"`
-	BorrowSupply           string   `json:"borrowSupply"`
-	MartgageRate           string   `json:"martgageRate"`
-	LendToken              string   `json:"lendToken"`
-	LendTokenSymbol        string   `json:"lend_token_symbol"`
-	BorrowToken            string   `json:"borrowToken"`
-	BorrowTokenSymbol      string   `json:"borrow_token_symbol"`
-	State                  string   `json:"state"`
-	SpCoin                 string   `json:"spCoin"`
-	JpCoin                 string   `json:"jpCoin"`
-	AutoLiquidateThreshold string   `json:"autoLiquidateThreshold"`
-	Pooldata               PoolData `json:"pooldata"`
+	PoolID                 int          `json:"pool_id"`
+	SettleTime             string       `json:"settleTime"`
+	EndTime                string       `json:"endTime"`
+	InterestRate           string       `json:"interestRate"`
+	MaxSupply              string       `json:"maxSupply"`
+	LendSupply             string       `json:"lendSupply"`
+	BorrowSupply           string       `json:"borrowSupply"`
+	MartgageRate           string       `json:"martgageRate"`
+	LendToken              string       `json:"lendToken"`
+	LendTokenSymbol        string       `json:"lend_token_symbol"`
+	BorrowToken            string       `json:"borrowToken"`
+	BorrowTokenSymbol      string       `json:"borrow_token_symbol"`
+	State                  string       `json:"state"`
+	SpCoin                 string       `json:"spCoin"`
+	JpCoin                 string       `json:"jpCoin"`
+	AutoLiquidateThreshold string       `json:"autoLiquidateThreshold"`
+	Pooldata               PoolData     `json:"pooldata"`
+	LendTokenAddress       string       `json:"-"`
+	BorrowTokenAddress     string       `json:"-"`
+	Metrics                *PoolMetrics `json:"metrics"`
 }
 
 func NewPool() *Pool {
@@ -69,7 +72,19 @@ func (p *Pool) Pagination(req *request.Search, whereCondition string) (error, in
 			JpCoin:                 b.JpCoin,
 			AutoLiquidateThreshold: b.AutoLiquidateThreshold,
 			Pooldata:               poolData,
+			LendTokenAddress:       b.LendToken,
+			BorrowTokenAddress:     b.BorrowToken,
 		})
 	}
 	return nil, total, pools
 }
+
+// ChainPools all pool bases of a chain
+func (p *Pool) ChainPools(chainId int) (error, []models.PoolBase) {
+	poolBase := []models.PoolBase{}
+	err := db.Mysql.Table("poolbases").Where("chain_id=?", chainId).Order("pool_id asc").Find(&poolBase).Debug().Error
+	if err != nil {
+		return err, nil
+	}
+	return nil, poolBase
+}
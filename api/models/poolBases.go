@@ -22,6 +22,9 @@ type PoolBaseInfo struct {
 	SettleTime             string          `json:"settleTime"`
 	SpCoin                 string          `json:"spCoin"`
 	State                  string          `json:"state"`
+	BlockNumber            uint64          `json:"blockNumber"` // block the pool was read at
+	BlockHash              string          `json:"blockHash"`
+	Metrics                *PoolMetrics    `json:"metrics"`
 }
 
 type PoolBases struct {
@@ -29,7 +32,7 @@ type PoolBases struct {
 	PoolID                 int    `json:"pool_id" gorm:"column:pool_id;"`
 	AutoLiquidateThreshold string `json:"autoLiquidateThreshold" gorm:"column:auto_liquidata_threshold;"`
 	BorrowSupply           string `json:"borrowSupply" gorm:"column:borrow_supply;"`
-	BorrowToken            string `json:"borrowToken" gorm:"column:pool_id;"`
+	BorrowToken            string `json:"borrowToken" gorm:"column:borrow_token;"`
 	BorrowTokenInfo        string `json:"borrowTokenInfo" gorm:"column:borrow_token_info;"`
 	EndTime                string `json:"endTime" gorm:"end_time;"`
 	InterestRate           string `json:"interestRate" gorm:"column:interest_rate;"`
@@ -42,6 +45,8 @@ type PoolBases struct {
 	SettleTime             string `json:"settleTime" gorm:"column:settle_time;"`
 	SpCoin                 string `json:"spCoin" gorm:"column:sp_coin;"`
 	State                  string `json:"state" gorm:"column:state;"`
+	BlockNumber            uint64 `json:"blockNumber" gorm:"column:block_number;"`
+	BlockHash              string `json:"blockHash" gorm:"column:block_hash;"`
 }
 
 type BorrowTokenInfo struct {
@@ -103,6 +108,8 @@ func (p *PoolBases) PoolBaseInfo(chainId int, res *[]PoolBaseInfoRes) error {
 				SettleTime:             v.SettleTime,
 				SpCoin:                 v.SpCoin,
 				State:                  v.State,
+				BlockNumber:            v.BlockNumber,
+				BlockHash:              v.BlockHash,
 			},
 		})
 	}
@@ -14,6 +14,8 @@ type PoolData struct {
 	LiquidationAmounLend   string `json:"liquidation_amoun_lend" gorm:"column:liquidation_amoun_lend"`
 	SettleAmountBorrow     string `json:"settle_amount_borrow" gorm:"column:settle_amount_borrow"`
 	SettleAmountLend       string `json:"settle_amount_lend" gorm:"column:settle_amount_lend"`
+	BlockNumber            uint64 `json:"block_number" gorm:"column:block_number"` // block the pool was read at
+	BlockHash              string `json:"block_hash" gorm:"column:block_hash"`
 	CreatedAt              string `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt              string `json:"updated_at" gorm:"column:updated_at"`
 }
@@ -0,0 +1,15 @@
+package models
+
+// PoolMetrics values computed from the raw pool fields with token decimals and oracle prices,
+// a field is empty when the token price or decimals are unknown
+type PoolMetrics struct {
+	Utilization            string `json:"utilization"`            // lend supply that borrow collateral can match at settle / lend supply
+	LendTvlUsd             string `json:"lendTvlUsd"`             // lend supply in USD
+	BorrowTvlUsd           string `json:"borrowTvlUsd"`           // borrow collateral supply in USD
+	TvlUsd                 string `json:"tvlUsd"`                 // lend + borrow in USD
+	CollateralizationRatio string `json:"collateralizationRatio"` // borrow collateral USD / lend supply USD, compare with martgageRate
+	InterestRate           string `json:"interestRate"`           // yearly interest rate of the contract
+	DurationDays           string `json:"durationDays"`           // settleTime to endTime
+	PeriodInterest         string `json:"periodInterest"`         // interest paid for the pool duration
+	EffectiveApy           string `json:"effectiveApy"`           // period interest compounded over a year
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"pledge-backend/db"
+
+	"gorm.io/gorm"
+)
+
+type PoolSnapshot struct {
+	Id                     int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId                string `json:"chain_id" gorm:"column:chain_id"`
+	PoolId                 int    `json:"pool_id" gorm:"column:pool_id"`
+	SnapshotTime           int64  `json:"snapshot_time" gorm:"column:snapshot_time"`
+	State                  string `json:"state" gorm:"column:state"`
+	InterestRate           string `json:"interest_rate" gorm:"column:interest_rate"`
+	MaxSupply              string `json:"max_supply" gorm:"column:max_supply"`
+	LendSupply             string `json:"lend_supply" gorm:"column:lend_supply"`
+	BorrowSupply           string `json:"borrow_supply" gorm:"column:borrow_supply"`
+	FinishAmountBorrow     string `json:"finish_amount_borrow" gorm:"column:finish_amount_borrow"`
+	FinishAmountLend       string `json:"finish_amount_lend" gorm:"column:finish_amount_lend"`
+	LiquidationAmounBorrow string `json:"liquidation_amoun_borrow" gorm:"column:liquidation_amoun_borrow"`
+	LiquidationAmounLend   string `json:"liquidation_amoun_lend" gorm:"column:liquidation_amoun_lend"`
+	SettleAmountBorrow     string `json:"settle_amount_borrow" gorm:"column:settle_amount_borrow"`
+	SettleAmountLend       string `json:"settle_amount_lend" gorm:"column:settle_amount_lend"`
+	CreatedAt              string `json:"created_at" gorm:"column:created_at"`
+}
+
+func NewPoolSnapshot() *PoolSnapshot {
+	return &PoolSnapshot{}
+}
+
+func (p *PoolSnapshot) TableName() string {
+	return "pool_snapshots"
+}
+
+// PoolHistory snapshots of a pool in [from, to] ordered by time
+func (p *PoolSnapshot) PoolHistory(chainId, poolId int, from, to int64) ([]PoolSnapshot, error) {
+	var snapshots []PoolSnapshot
+	err := db.Mysql.Table("pool_snapshots").Where("chain_id=? and pool_id=? and snapshot_time>=? and snapshot_time<=?", chainId, poolId, from, to).
+		Order("snapshot_time asc, id asc").Find(&snapshots).Debug().Error
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// LastPoolSnapshot the latest snapshot before the given time, the bool is false if there is none
+func (p *PoolSnapshot) LastPoolSnapshot(chainId, poolId int, before int64) (PoolSnapshot, bool, error) {
+	snapshot := PoolSnapshot{}
+	err := db.Mysql.Table("pool_snapshots").Where("chain_id=? and pool_id=? and snapshot_time<?", chainId, poolId, before).
+		Order("snapshot_time desc, id desc").First(&snapshot).Debug().Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return snapshot, false, nil
+		}
+		return snapshot, false, err
+	}
+	return snapshot, true, nil
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	consts "pledge-backend/api/common"
+	"pledge-backend/db"
+	"pledge-backend/utils"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Proposal admin call of the pledge pool contract, executed once enough multi-sign accounts signed it
+type Proposal struct {
+	Id         int     `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId    int     `json:"chain_id" gorm:"column:chain_id;index:idx_chain_status,priority:1"`
+	Target     string  `json:"target" gorm:"column:target;type:varchar(42)"` // pledge pool contract
+	Method     string  `json:"method" gorm:"column:method;type:varchar(50)"`
+	Params     string  `json:"params" gorm:"column:params;type:text"` // json array of the call arguments
+	Data       string  `json:"data" gorm:"column:data;type:text"`     // abi encoded call, 0x hex
+	Value      string  `json:"value" gorm:"column:value;type:varchar(80)"`
+	Threshold  int     `json:"threshold" gorm:"column:threshold"`
+	Deadline   int64   `json:"deadline" gorm:"column:deadline"` // unix seconds, part of the signed message
+	Status     string  `json:"status" gorm:"column:status;type:varchar(20);index:idx_chain_status,priority:2"`
+	TxHash     *string `json:"tx_hash" gorm:"column:tx_hash;type:varchar(66);uniqueIndex"` // null until executed, a transaction executes one proposal
+	CreatedBy  string  `json:"created_by" gorm:"column:created_by;type:varchar(64)"`
+	ExecutedBy string  `json:"executed_by" gorm:"column:executed_by;type:varchar(64)"`
+	CreatedAt  string  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt  string  `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// ProposalSignature EIP-712 signature of a proposal by one multi-sign account
+type ProposalSignature struct {
+	Id         int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	ProposalId int    `json:"proposal_id" gorm:"column:proposal_id;uniqueIndex:uk_proposal_signer,priority:1"`
+	Signer     string `json:"signer" gorm:"column:signer;type:varchar(42);uniqueIndex:uk_proposal_signer,priority:2"`
+	Signature  string `json:"signature" gorm:"column:signature;type:varchar(132)"`
+	CreatedBy  string `json:"created_by" gorm:"column:created_by;type:varchar(64)"`
+	CreatedAt  string `json:"created_at" gorm:"column:created_at"`
+}
+
+func NewProposal() *Proposal {
+	return &Proposal{}
+}
+
+func (p *Proposal) TableName() string {
+	return "proposals"
+}
+
+func (p *ProposalSignature) TableName() string {
+	return "proposal_signatures"
+}
+
+// Create Save a drafted proposal
+func (p *Proposal) Create(proposal *Proposal) error {
+	nowDateTime := utils.GetCurDateTimeFormat()
+	proposal.Status = consts.PROPOSAL_STATUS_PENDING
+	proposal.CreatedAt = nowDateTime
+	proposal.UpdatedAt = nowDateTime
+	return db.Mysql.Table("proposals").Create(proposal).Debug().Error
+}
+
+// GetById Get a proposal, gorm.ErrRecordNotFound if there is none
+func (p *Proposal) GetById(id int) (Proposal, error) {
+	proposal := Proposal{}
+	err := db.Mysql.Table("proposals").Where("id=?", id).First(&proposal).Debug().Error
+	return proposal, err
+}
+
+// GetByTxHash Get the proposal executed by a transaction, gorm.ErrRecordNotFound if there is none
+func (p *Proposal) GetByTxHash(txHash string) (Proposal, error) {
+	proposal := Proposal{}
+	err := db.Mysql.Table("proposals").Where("tx_hash=?", txHash).First(&proposal).Debug().Error
+	return proposal, err
+}
+
+// List proposals of a chain, newest first, status is optional
+func (p *Proposal) List(chainId int, status string) ([]Proposal, error) {
+	proposals := make([]Proposal, 0)
+	tx := db.Mysql.Table("proposals").Where("chain_id=?", chainId)
+	if status != "" {
+		tx = tx.Where("status=?", status)
+	}
+	err := tx.Order("id desc").Find(&proposals).Debug().Error
+	if err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// Signatures of a proposal, ordered by signer address as expected by the multi-sign check
+func (p *Proposal) Signatures(proposalId int) ([]ProposalSignature, error) {
+	signatures := make([]ProposalSignature, 0)
+	err := db.Mysql.Table("proposal_signatures").Where("proposal_id=?", proposalId).Order("signer asc").Find(&signatures).Debug().Error
+	if err != nil {
+		return nil, err
+	}
+	return signatures, nil
+}
+
+// AddSignature Save a signature and approve the proposal once threshold signers are still multi-sign accounts.
+// It returns the number of counted signatures, the unique key rejects a second signature of the same signer.
+// The proposal row is locked so concurrent signatures are counted one after the other.
+func (p *Proposal) AddSignature(proposal *Proposal, signature *ProposalSignature) (int64, error) {
+	accounts, err := NewMultiSign().Accounts(proposal.ChainId)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = db.Mysql.Transaction(func(tx *gorm.DB) error {
+		locked := Proposal{}
+		err := tx.Table("proposals").Clauses(clause.Locking{Strength: "UPDATE"}).Where("id=?", proposal.Id).First(&locked).Debug().Error
+		if err != nil {
+			return err
+		}
+		signature.CreatedAt = utils.GetCurDateTimeFormat()
+		err = tx.Table("proposal_signatures").Create(signature).Debug().Error
+		if err != nil {
+			return err
+		}
+		var signers []string
+		err = tx.Table("proposal_signatures").Where("proposal_id=?", proposal.Id).Pluck("signer", &signers).Debug().Error
+		if err != nil {
+			return err
+		}
+		// a signer removed from the multi-sign accounts does not count anymore
+		count = 0
+		for _, signer := range signers {
+			for _, account := range accounts {
+				if strings.EqualFold(signer, account) {
+					count++
+					break
+				}
+			}
+		}
+		if locked.Status != consts.PROPOSAL_STATUS_PENDING || count < int64(locked.Threshold) {
+			return nil
+		}
+		return tx.Table("proposals").Where("id=?", proposal.Id).Updates(map[string]interface{}{
+			"status":     consts.PROPOSAL_STATUS_APPROVED,
+			"updated_at": utils.GetCurDateTimeFormat(),
+		}).Debug().Error
+	})
+	return count, err
+}
+
+// SetExecuted Save the hash of the transaction that executed an approved proposal
+func (p *Proposal) SetExecuted(id int, txHash, operator string) error {
+	return db.Mysql.Table("proposals").Where("id=? and status=?", id, consts.PROPOSAL_STATUS_APPROVED).Updates(map[string]interface{}{
+		"status":      consts.PROPOSAL_STATUS_EXECUTED,
+		"tx_hash":     txHash,
+		"executed_by": operator,
+		"updated_at":  utils.GetCurDateTimeFormat(),
+	}).Debug().Error
+}
@@ -9,8 +9,9 @@ type Receipt struct {
 	GasUsed         uint64 `json:"gasUsed" gorm:"column:gas_used"`
 	ContractAddress string `json:"contractAddress" gorm:"column:contract_address"`
 	BlockNumber     uint64 `json:"blockNumber" gorm:"column:block_number"`
-	BlockHash       string `json:"blockHash" gorm:"column:block_hash"`
+	BlockHash       string `json:"blockHash" gorm:"column:block_hash;type:varchar(66);index"`
 	Type            uint8  `json:"type" gorm:"column:type"`
+	Canonical       bool   `json:"canonical" gorm:"column:canonical;default:true"` // false once its block was reorged out
 }
 
 func (r *Receipt) TableName() string {
@@ -26,5 +27,6 @@ func NewReceipt(receipt *types.Receipt) *Receipt {
 		BlockNumber:     receipt.BlockNumber.Uint64(),
 		BlockHash:       receipt.BlockHash.String(),
 		Type:            receipt.Type,
+		Canonical:       true,
 	}
 }
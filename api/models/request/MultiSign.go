@@ -16,3 +16,18 @@ type SetMultiSign struct {
 type GetMultiSign struct {
 	ChainId int `json:"chain_id"`
 }
+
+type MultiSignHistory struct {
+	ChainId int `form:"chain_id" binding:"required"`
+}
+
+type MultiSignDiff struct {
+	ChainId int `form:"chain_id" binding:"required"`
+	From    int `form:"from" binding:"required"` // version
+	To      int `form:"to" binding:"required"`   // version
+}
+
+type RollbackMultiSign struct {
+	ChainId int `json:"chain_id" binding:"required"`
+	Version int `json:"version" binding:"required"`
+}
@@ -4,3 +4,36 @@ type Login struct {
 	Name     string `form:"name" binding:"required"`
 	Password string `form:"password" binding:"required"`
 }
+
+type CreateAdminUser struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+type UpdateAdminUser struct {
+	Id       int    `json:"id" binding:"required"`
+	Password string `json:"password"` // unchanged if empty
+	Role     string `json:"role" binding:"required"`
+}
+
+type DeleteAdminUser struct {
+	Id int `json:"id" binding:"required"`
+}
+
+type SiweLogin struct {
+	Message   string `json:"message" binding:"required"`   // EIP-4361 message
+	Signature string `json:"signature" binding:"required"` // personal_sign signature, 0x hex
+}
+
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type RevokeSession struct {
+	SessionId string `json:"session_id" binding:"required"`
+}
+
+type AdminUserSessions struct {
+	Id int `form:"id" binding:"required"`
+}
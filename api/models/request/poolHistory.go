@@ -0,0 +1,9 @@
+package request
+
+type PoolHistory struct {
+	ChainId  int    `form:"chainId" binding:"required"`
+	PoolId   int    `form:"poolId" binding:"required"`
+	From     int64  `form:"from"`     // unix seconds, default to - 7 days
+	To       int64  `form:"to"`       // unix seconds, default now
+	Interval string `form:"interval"` // 5m 15m 1h 4h 1d 1w, default 1h
+}
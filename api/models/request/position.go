@@ -0,0 +1,6 @@
+package request
+
+type Positions struct {
+	ChainId int    `form:"chainId" binding:"required"`
+	Address string `form:"-"`
+}
@@ -0,0 +1,9 @@
+package request
+
+type PriceHistory struct {
+	ChainId  int    `form:"chainId" binding:"required"`
+	Token    string `form:"token" binding:"required"`
+	From     int64  `form:"from"`     // unix seconds, default to - 1 day
+	To       int64  `form:"to"`       // unix seconds, default now
+	Interval string `form:"interval"` // 1m 1h 1d, default 1h
+}
@@ -0,0 +1,26 @@
+package request
+
+type CreateProposal struct {
+	ChainId int      `json:"chain_id" binding:"required"`
+	Method  string   `json:"method" binding:"required"` // admin method of the pledge pool, e.g. setFee
+	Params  []string `json:"params"`                    // call arguments in abi order, numbers in decimal
+}
+
+type ProposalList struct {
+	ChainId int    `form:"chain_id" binding:"required"`
+	Status  string `form:"status"`
+}
+
+type ProposalId struct {
+	Id int `form:"id" binding:"required"`
+}
+
+type SignProposal struct {
+	Id        int    `json:"id" binding:"required"`
+	Signature string `json:"signature" binding:"required"` // eth_signTypedData_v4 signature of the typed data of the proposal
+}
+
+type ExecuteProposal struct {
+	Id     int    `json:"id" binding:"required"`
+	TxHash string `json:"tx_hash" binding:"required"`
+}
@@ -1,5 +1,12 @@
 package response
 
 type Login struct {
-	TokenId string `json:"token_id"`
+	TokenId      string `json:"token_id"`      // access token, sent as authCode
+	RefreshToken string `json:"refresh_token"` // single use, exchanged at /user/refresh
+	ExpireAt     int64  `json:"expire_at"`     // access token expiry, unix seconds
+	Role         string `json:"role"`
+}
+
+type Nonce struct {
+	Nonce string `json:"nonce"`
 }
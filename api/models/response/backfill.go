@@ -0,0 +1,14 @@
+package response
+
+// BackfillStatus progress of the block backfill of the configured range
+type BackfillStatus struct {
+	Enabled     bool   `json:"enabled"`
+	StartBlock  uint64 `json:"start_block"`
+	EndBlock    uint64 `json:"end_block"` // 0 follows the finalized head
+	NextBlock   uint64 `json:"next_block"`
+	TargetBlock uint64 `json:"target_block"`
+	Remaining   uint64 `json:"remaining"` // blocks up to target_block that are not saved yet
+	Status      string `json:"status"`    // running, synced, done or failed, empty if the range was never run
+	Error       string `json:"error"`
+	UpdatedAt   string `json:"updated_at"`
+}
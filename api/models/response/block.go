@@ -4,6 +4,7 @@ import "pledge-backend/api/models"
 
 type Block struct {
 	Hash            string
+	ParentHash      string
 	Nonce           uint64
 	Number          uint64
 	Time            uint64
@@ -14,6 +15,7 @@ type Block struct {
 func NewBlock(block *models.Block) *Block {
 	return &Block{
 		Hash:         block.Hash,
+		ParentHash:   block.ParentHash,
 		Nonce:        block.Nonce,
 		Number:       block.Number,
 		Time:         block.Time,
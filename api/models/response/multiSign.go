@@ -12,3 +12,36 @@ type MultiSign struct {
 	JpHash           string   `json:"jpHash"`
 	MultiSignAccount []string `json:"multi_sign_account"`
 }
+
+// MultiSignVersion one version of the multi-sign signature
+type MultiSignVersion struct {
+	MultiSign
+	Version      int    `json:"version"`
+	IsActive     bool   `json:"is_active"`
+	RollbackFrom int    `json:"rollback_from"`
+	CreatedBy    string `json:"created_by"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// MultiSignDiff changes from one version to another
+type MultiSignDiff struct {
+	ChainId         int                    `json:"chain_id"`
+	From            int                    `json:"from"`
+	To              int                    `json:"to"`
+	Changes         []MultiSignFieldChange `json:"changes"`
+	AccountsAdded   []string               `json:"accounts_added"`
+	AccountsRemoved []string               `json:"accounts_removed"`
+}
+
+type MultiSignFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// MultiSignCheckErr a field of a posted multi-sign that failed verification, code is a statecode
+type MultiSignCheckErr struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+	Code  int    `json:"code"`
+}
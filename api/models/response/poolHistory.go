@@ -0,0 +1,24 @@
+package response
+
+// PoolHistory bucketed time series of a pool
+type PoolHistory struct {
+	ChainId  int                `json:"chain_id"`
+	PoolId   int                `json:"pool_id"`
+	Interval string             `json:"interval"`
+	Points   []PoolHistoryPoint `json:"points"`
+}
+
+// PoolHistoryPoint last known values at the end of a bucket
+type PoolHistoryPoint struct {
+	Time                   int64  `json:"time"` // bucket start, unix seconds
+	State                  string `json:"state"`
+	InterestRate           string `json:"interest_rate"`
+	LendSupply             string `json:"lend_supply"`
+	BorrowSupply           string `json:"borrow_supply"`
+	FinishAmountBorrow     string `json:"finish_amount_borrow"`
+	FinishAmountLend       string `json:"finish_amount_lend"`
+	LiquidationAmounBorrow string `json:"liquidation_amoun_borrow"`
+	LiquidationAmounLend   string `json:"liquidation_amoun_lend"`
+	SettleAmountBorrow     string `json:"settle_amount_borrow"`
+	SettleAmountLend       string `json:"settle_amount_lend"`
+}
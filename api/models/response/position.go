@@ -0,0 +1,26 @@
+package response
+
+// UserPositions wallet positions of every pool on a chain
+type UserPositions struct {
+	Address   string         `json:"address"`
+	ChainId   int            `json:"chain_id"`
+	Positions []PoolPosition `json:"positions"`
+}
+
+type PoolPosition struct {
+	PoolId int           `json:"pool_id"`
+	State  string        `json:"state"`
+	Lend   *PositionSide `json:"lend,omitempty"`
+	Borrow *PositionSide `json:"borrow,omitempty"`
+}
+
+type PositionSide struct {
+	Token        string   `json:"token"`
+	TokenSymbol  string   `json:"token_symbol"`
+	TokenPrice   string   `json:"token_price"`
+	StakeAmount  string   `json:"stake_amount"`
+	RefundAmount string   `json:"refund_amount"`
+	HasNoRefund  bool     `json:"has_no_refund"`
+	HasNoClaim   bool     `json:"has_no_claim"`
+	Actions      []string `json:"actions"`
+}
@@ -0,0 +1,30 @@
+package response
+
+import "pledge-backend/api/models/kucoin"
+
+// PriceStatus ku-coin plgr price and the connection of the producer that streams it
+type PriceStatus struct {
+	Symbol         string                 `json:"symbol"`
+	Price          string                 `json:"price"`
+	UpdatedAt      int64                  `json:"updated_at"` // unix seconds of the last tick, 0 if none was stored
+	Stale          bool                   `json:"stale"`      // no tick within stale_threshold, the price is not valid
+	StaleThreshold int64                  `json:"stale_threshold"`
+	Producer       *kucoin.ProducerStatus `json:"producer"` // nil if no instance is producing
+}
+
+// PriceHistory OHLC candles of a token price, usd * 1e8 as the oracle
+type PriceHistory struct {
+	ChainId  int           `json:"chain_id"`
+	Token    string        `json:"token"`
+	Interval string        `json:"interval"`
+	Candles  []PriceCandle `json:"candles"`
+}
+
+// PriceCandle a bucket without a price change repeats the last close
+type PriceCandle struct {
+	Time  int64  `json:"time"` // bucket start, unix seconds
+	Open  string `json:"open"`
+	High  string `json:"high"`
+	Low   string `json:"low"`
+	Close string `json:"close"`
+}
@@ -0,0 +1,37 @@
+package response
+
+import (
+	"pledge-backend/api/models"
+
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+type ProposalDetail struct {
+	Proposal   models.Proposal            `json:"proposal"`
+	Signatures []models.ProposalSignature `json:"signatures"`
+	TypedData  apitypes.TypedData         `json:"typed_data"` // input of eth_signTypedData_v4
+	Digest     string                     `json:"digest"`     // EIP-712 hash that is signed
+}
+
+type ProposalSign struct {
+	Signer     string `json:"signer"`
+	Signatures int64  `json:"signatures"`
+	Threshold  int    `json:"threshold"`
+	Status     string `json:"status"`
+}
+
+// ProposalPayload fully signed call of an approved proposal.
+// The signatures are over the Proposal typed data of this backend and no contract verifies them, they are the
+// off-chain approvals of the call. The multi-sign wallet that executes it collects its own signatures.
+type ProposalPayload struct {
+	Id               int      `json:"id"`
+	ChainId          int      `json:"chain_id"`
+	Target           string   `json:"target"`
+	Data             string   `json:"data"`
+	Value            string   `json:"value"`
+	Deadline         int64    `json:"deadline"`
+	Digest           string   `json:"digest"`
+	Signers          []string `json:"signers"`           // ascending
+	Signatures       []string `json:"signatures"`        // same order as signers
+	PackedSignatures string   `json:"packed_signatures"` // signatures concatenated in signer order, not a Safe signatures argument
+}
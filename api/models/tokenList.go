@@ -22,6 +22,14 @@ type TokenList struct {
 	ChainId  int    `json:"chain_id" gorm:"column:chain_id"`
 }
 
+// TokenValue the token_info columns used to value an amount of a token
+type TokenValue struct {
+	Symbol   string `gorm:"column:symbol"`
+	Decimals int    `gorm:"column:decimals"`
+	Token    string `gorm:"column:token"`
+	Price    string `gorm:"column:price"`
+}
+
 func NewTokenInfo() *TokenInfo {
 	return &TokenInfo{}
 }
@@ -43,3 +51,12 @@ func (m *TokenInfo) GetTokenList(req *request.TokenList) (error, []TokenList) {
 	}
 	return nil, tokenList
 }
+
+func (m *TokenInfo) GetTokenValues(chainId int) (error, []TokenValue) {
+	var tokenValues = make([]TokenValue, 0)
+	err := db.Mysql.Table("token_info").Where("chain_id", chainId).Find(&tokenValues).Debug().Error
+	if err != nil {
+		return errors.New("record select err " + err.Error()), nil
+	}
+	return nil, tokenValues
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"pledge-backend/db"
+
+	"gorm.io/gorm"
+)
+
+// TokenPrice a price change or an hourly / daily candle of a token, written by the schedule
+type TokenPrice struct {
+	Id         int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId    string `json:"chain_id" gorm:"column:chain_id"`
+	Token      string `json:"token" gorm:"column:token"`
+	PriceTime  int64  `json:"price_time" gorm:"column:price_time"`
+	Resolution int64  `json:"resolution" gorm:"column:resolution"`
+	Open       string `json:"open" gorm:"column:open"`
+	High       string `json:"high" gorm:"column:high"`
+	Low        string `json:"low" gorm:"column:low"`
+	Close      string `json:"close" gorm:"column:close"`
+	CreatedAt  string `json:"created_at" gorm:"column:created_at"`
+}
+
+func NewTokenPrice() *TokenPrice {
+	return &TokenPrice{}
+}
+
+func (t *TokenPrice) TableName() string {
+	return "token_prices"
+}
+
+// PriceHistory rows of a token in [from, to] ordered by time
+func (t *TokenPrice) PriceHistory(chainId int, token string, from, to int64) ([]TokenPrice, error) {
+	var prices []TokenPrice
+	err := db.Mysql.Table("token_prices").Where("chain_id=? and token=? and price_time>=? and price_time<=?", chainId, token, from, to).
+		Order("price_time asc, id asc").Find(&prices).Debug().Error
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// LastTokenPrice the latest row before the given time, the bool is false if there is none
+func (t *TokenPrice) LastTokenPrice(chainId int, token string, before int64) (TokenPrice, bool, error) {
+	price := TokenPrice{}
+	err := db.Mysql.Table("token_prices").Where("chain_id=? and token=? and price_time<?", chainId, token, before).
+		Order("price_time desc, id desc").First(&price).Debug().Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return price, false, nil
+		}
+		return price, false, err
+	}
+	return price, true, nil
+}
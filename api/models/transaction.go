@@ -18,13 +18,15 @@ type Transaction struct {
 	Nonce       uint64          `json:"nonce" gorm:"column:nonce;"`
 	ToHash      string          `json:"toHash" gorm:"column:to_hash;"`
 	BlockNumber uint64          `json:"blockNumber" gorm:"column:block_number;"`
+	BlockHash   string          `json:"blockHash" gorm:"column:block_hash;type:varchar(66);index"`
+	Canonical   bool            `json:"canonical" gorm:"column:canonical;default:true"` // false once its block was reorged out
 }
 
 func (t *Transaction) TableName() string {
 	return "transaction"
 }
 
-func NewTransaction(tx *types.Transaction, blockNumber uint64) *Transaction {
+func NewTransaction(tx *types.Transaction, blockNumber uint64, blockHash string) *Transaction {
 	return &Transaction{
 		Hash:        tx.Hash().Hex(),
 		Value:       decimal.NewFromBigInt(tx.Value(), 0),
@@ -33,18 +35,20 @@ func NewTransaction(tx *types.Transaction, blockNumber uint64) *Transaction {
 		Nonce:       tx.Nonce(),
 		ToHash:      tx.To().String(),
 		BlockNumber: blockNumber,
+		BlockHash:   blockHash,
+		Canonical:   true,
 	}
 }
 
 // 插入通过查询区块获取到的交易数据，先删后插
-func (t *Transaction) InsertFromBlock(transactionList []*Transaction, blockNumber uint64) error {
+func (t *Transaction) InsertFromBlock(transactionList []*Transaction, blockHash string) error {
 	return db.Mysql.Transaction(func(tx *gorm.DB) error {
-		err := db.Mysql.Exec("delete from transaction where block_number = ?", blockNumber).Debug().Error
+		err := tx.Exec("delete from transaction where block_hash = ?", blockHash).Debug().Error
 		if err != nil {
 			log.Logger.Error(err.Error())
 			return err
 		}
-		err = db.Mysql.Table("transaction").Create(&transactionList).Debug().Error
+		err = tx.Table("transaction").Create(&transactionList).Debug().Error
 		if err != nil {
 			log.Logger.Error(err.Error())
 			return err
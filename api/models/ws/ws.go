@@ -2,11 +2,11 @@ package ws
 
 import (
 	"encoding/json"
-	"errors"
 	"github.com/gorilla/websocket"
-	"pledge-backend/api/models/kucoin"
 	"pledge-backend/config"
 	"pledge-backend/log"
+	"pledge-backend/pubsub"
+	"strings"
 	"sync"
 	"time"
 )
@@ -15,14 +15,28 @@ const SuccessCode = 0
 const PongCode = 1
 const ErrorCode = -1
 
+// MaxTopics topics one connection can subscribe to
+const MaxTopics = 100
+
+// client ops
+const (
+	OpSubscribe   = "subscribe"
+	OpUnsubscribe = "unsubscribe"
+	OpPing        = "ping"
+)
+
 type Server struct {
 	sync.Mutex
 	Id       string
 	Socket   *websocket.Conn
-	Send     chan []byte
-	LastTime int64 // last send time
+	Send     chan []byte // marshaled messages of subscribed topics
+	LastTime int64       // last send time
+
+	topicMu sync.RWMutex
+	topics  map[string]bool
 }
 
+// ServerManager connections of this api instance
 type ServerManager struct {
 	Servers    sync.Map
 	Broadcast  chan []byte
@@ -31,49 +45,96 @@ type ServerManager struct {
 }
 
 type Message struct {
-	Code int    `json:"code"`
-	Data string `json:"data"`
+	Code  int         `json:"code"`
+	Op    string      `json:"op,omitempty"`
+	Topic string      `json:"topic,omitempty"`
+	Data  interface{} `json:"data"`
+}
+
+// Request subscribe / unsubscribe / ping sent by a client
+type Request struct {
+	Op    string `json:"op"`
+	Topic string `json:"topic"`
 }
 
 var Manager = ServerManager{}
 var UserPingPongDurTime = config.Config.Env.WssTimeoutDuration // seconds
 
 func (s *Server) SendToClient(data string, code int) {
-	s.Lock()
-	defer s.Unlock()
-
-	dataBytes, err := json.Marshal(Message{
+	s.write(Message{
 		Code: code,
 		Data: data,
 	})
-	err = s.Socket.WriteMessage(websocket.TextMessage, dataBytes)
+}
+
+func (s *Server) write(message Message) {
+	dataBytes, err := json.Marshal(message)
+	if err != nil {
+		log.Logger.Sugar().Error(s.Id+" marshal err ", err)
+		return
+	}
+	s.writeBytes(dataBytes)
+}
+
+func (s *Server) writeBytes(dataBytes []byte) {
+	s.Lock()
+	defer s.Unlock()
+
+	err := s.Socket.WriteMessage(websocket.TextMessage, dataBytes)
 	if err != nil {
 		log.Logger.Sugar().Error(s.Id+" SendToClient err ", err)
 	}
 }
 
+// Subscribe add a topic, false if the connection has too many topics
+func (s *Server) Subscribe(topic string) bool {
+	s.topicMu.Lock()
+	defer s.topicMu.Unlock()
+	if s.topics == nil {
+		s.topics = make(map[string]bool)
+	}
+	if !s.topics[topic] && len(s.topics) >= MaxTopics {
+		return false
+	}
+	s.topics[topic] = true
+	return true
+}
+
+func (s *Server) Unsubscribe(topic string) {
+	s.topicMu.Lock()
+	defer s.topicMu.Unlock()
+	delete(s.topics, topic)
+}
+
+func (s *Server) IsSubscribed(topic string) bool {
+	s.topicMu.RLock()
+	defer s.topicMu.RUnlock()
+	return s.topics[topic]
+}
+
 func (s *Server) ReadAndWrite() {
 
-	errChan := make(chan error)
+	errChan := make(chan error, 2)
+	done := make(chan struct{})
 
+	// the /price endpoint always pushed the PLGR price, a new connection keeps getting it until it unsubscribes
+	s.Subscribe(pubsub.MarketPriceTopic(pubsub.MarketPriceSymbol))
 	Manager.Servers.Store(s.Id, s)
 
 	defer func() {
-		Manager.Servers.Delete(s)
+		Manager.Servers.Delete(s.Id)
+		close(done)
 		_ = s.Socket.Close()
-		close(s.Send)
 	}()
 
 	//write
 	go func() {
 		for {
 			select {
-			case message, ok := <-s.Send:
-				if !ok {
-					errChan <- errors.New("write message error")
-					return
-				}
-				s.SendToClient(string(message), SuccessCode)
+			case message := <-s.Send:
+				s.writeBytes(message)
+			case <-done:
+				return
 			}
 		}
 	}()
@@ -93,9 +154,10 @@ func (s *Server) ReadAndWrite() {
 			if string(message) == "ping" || string(message) == `"ping"` || string(message) == "'ping'" {
 				s.LastTime = time.Now().Unix()
 				s.SendToClient("pong", PongCode)
+				continue
 			}
-			continue
 
+			s.handleRequest(message)
 		}
 	}()
 
@@ -114,17 +176,77 @@ func (s *Server) ReadAndWrite() {
 	}
 }
 
+// handleRequest answer a json subscribe / unsubscribe / ping request
+func (s *Server) handleRequest(message []byte) {
+	req := Request{}
+	err := json.Unmarshal(message, &req)
+	if err != nil {
+		s.SendToClient("invalid request", ErrorCode)
+		return
+	}
+
+	switch strings.ToLower(req.Op) {
+	case OpPing:
+		s.LastTime = time.Now().Unix()
+		s.write(Message{Code: PongCode, Op: OpPing, Data: "pong"})
+	case OpSubscribe:
+		topic, ok := pubsub.NormalizeTopic(req.Topic)
+		if !ok {
+			s.write(Message{Code: ErrorCode, Op: OpSubscribe, Topic: req.Topic, Data: "invalid topic"})
+			return
+		}
+		if !s.Subscribe(topic) {
+			s.write(Message{Code: ErrorCode, Op: OpSubscribe, Topic: topic, Data: "too many topics"})
+			return
+		}
+		s.write(Message{Code: SuccessCode, Op: OpSubscribe, Topic: topic, Data: "subscribed"})
+	case OpUnsubscribe:
+		topic, ok := pubsub.NormalizeTopic(req.Topic)
+		if !ok {
+			s.write(Message{Code: ErrorCode, Op: OpUnsubscribe, Topic: req.Topic, Data: "invalid topic"})
+			return
+		}
+		s.Unsubscribe(topic)
+		s.write(Message{Code: SuccessCode, Op: OpUnsubscribe, Topic: topic, Data: "unsubscribed"})
+	default:
+		s.write(Message{Code: ErrorCode, Op: req.Op, Data: "unknown op"})
+	}
+}
+
+// Dispatch queue a topic update to the connections subscribed to it, a connection whose queue is full misses it
+func (m *ServerManager) Dispatch(topic string, data interface{}) {
+	dataBytes, err := json.Marshal(Message{
+		Code:  SuccessCode,
+		Topic: topic,
+		Data:  data,
+	})
+	if err != nil {
+		log.Logger.Sugar().Error("ws Dispatch marshal err ", topic, " ", err)
+		return
+	}
+	m.Servers.Range(func(key, value interface{}) bool {
+		server := value.(*Server)
+		if server.IsSubscribed(topic) {
+			select {
+			case server.Send <- dataBytes:
+			default:
+				log.Logger.Sugar().Info("ws send queue full ", server.Id, " ", topic)
+			}
+		}
+		return true
+	})
+}
+
 func StartServer() {
 	log.Logger.Info("WsServer start")
+
+	// every api instance fans out the updates to its own connections: the exchange price of the elected
+	// producer and the pool, price and position updates of the schedule process
 	for {
-		select {
-		case price, ok := <-kucoin.PlgrPriceChan:
-			if ok {
-				Manager.Servers.Range(func(key, value interface{}) bool {
-					value.(*Server).SendToClient(price, SuccessCode)
-					return true
-				})
-			}
-		}
+		err := pubsub.Subscribe(func(topic string, data []byte) {
+			Manager.Dispatch(topic, json.RawMessage(data))
+		})
+		log.Logger.Sugar().Error("ws pubsub subscribe err ", err)
+		time.Sleep(5 * time.Second)
 	}
 }
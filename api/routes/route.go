@@ -1,6 +1,7 @@
 package routes
 
 import (
+	consts "pledge-backend/api/common"
 	"pledge-backend/api/controllers"
 	"pledge-backend/api/middlewares"
 	"pledge-backend/config"
@@ -15,26 +16,65 @@ func InitRoute(e *gin.Engine) *gin.Engine {
 
 	// pledge-defi backend
 	poolController := controllers.PoolController{}
-	v2Group.GET("/poolBaseInfo", poolController.PoolBaseInfo)                                   //pool base information
-	v2Group.GET("/poolDataInfo", poolController.PoolDataInfo)                                   //pool data information
-	v2Group.GET("/token", poolController.TokenList)                                             //pool token information
-	v2Group.POST("/pool/debtTokenList", middlewares.CheckToken(), poolController.DebtTokenList) //pool debtTokenList
-	v2Group.POST("/pool/search", middlewares.CheckToken(), poolController.Search)               //pool search
+	v2Group.GET("/poolBaseInfo", poolController.PoolBaseInfo)                                                                                    //pool base information
+	v2Group.GET("/poolDataInfo", poolController.PoolDataInfo)                                                                                    //pool data information
+	v2Group.GET("/poolHistory", poolController.PoolHistory)                                                                                      //pool time series
+	v2Group.GET("/token", poolController.TokenList)                                                                                              //pool token information
+	v2Group.POST("/pool/debtTokenList", middlewares.CheckToken(), middlewares.CheckRole(consts.ADMIN_ROLE_VIEWER), poolController.DebtTokenList) //pool debtTokenList
+	v2Group.POST("/pool/search", middlewares.CheckToken(), middlewares.CheckRole(consts.ADMIN_ROLE_VIEWER), poolController.Search)               //pool search
+
+	// wallet positions
+	positionController := controllers.PositionController{}
+	v2Group.GET("/user/:address/positions", positionController.Positions) //lend and borrow positions of a wallet
 
 	// plgr-usdt price
 	priceController := controllers.PriceController{}
-	v2Group.GET("/price", priceController.NewPrice) //new price on ku-coin-exchange
+	v2Group.GET("/price", priceController.NewPrice)             //new price on ku-coin-exchange
+	v2Group.GET("/price/status", priceController.PriceStatus)   //plgr price staleness and ku-coin stream state
+	v2Group.GET("/price/history", priceController.PriceHistory) //token price ohlc candles
 
 	// pledge-defi admin backend
 	multiSignPoolController := controllers.MultiSignPoolController{}
-	v2Group.POST("/pool/setMultiSign", middlewares.CheckToken(), multiSignPoolController.SetMultiSign) //multi-sign set
-	v2Group.POST("/pool/getMultiSign", middlewares.CheckToken(), multiSignPoolController.GetMultiSign) //multi-sign get
+	v2Group.POST("/pool/setMultiSign", middlewares.CheckToken(), middlewares.CheckRole(consts.ADMIN_ROLE_MULTISIG_ADMIN), multiSignPoolController.SetMultiSign)  //multi-sign set
+	v2Group.POST("/pool/getMultiSign", middlewares.CheckToken(), middlewares.CheckRole(consts.ADMIN_ROLE_OPERATOR), multiSignPoolController.GetMultiSign)        //multi-sign get
+	v2Group.GET("/pool/multiSignHistory", middlewares.CheckToken(), middlewares.CheckRole(consts.ADMIN_ROLE_OPERATOR), multiSignPoolController.MultiSignHistory) //multi-sign versions
+	v2Group.GET("/pool/multiSignDiff", middlewares.CheckToken(), middlewares.CheckRole(consts.ADMIN_ROLE_OPERATOR), multiSignPoolController.MultiSignDiff)       //multi-sign changes between two versions
+
+	// multi-sign proposals of pledge pool admin calls
+	proposalController := controllers.ProposalController{}
+	proposalGroup := v2Group.Group("/proposal", middlewares.CheckToken())
+	proposalGroup.POST("/create", middlewares.CheckRole(consts.ADMIN_ROLE_OPERATOR), proposalController.CreateProposal)   // draft an admin call
+	proposalGroup.GET("/list", middlewares.CheckRole(consts.ADMIN_ROLE_VIEWER), proposalController.ProposalList)          // proposals of a chain
+	proposalGroup.GET("/detail", middlewares.CheckRole(consts.ADMIN_ROLE_VIEWER), proposalController.ProposalDetail)      // proposal, signatures and typed data to sign
+	proposalGroup.POST("/sign", middlewares.CheckRole(consts.ADMIN_ROLE_OPERATOR), proposalController.SignProposal)       // add an EIP-712 signature
+	proposalGroup.GET("/payload", middlewares.CheckRole(consts.ADMIN_ROLE_OPERATOR), proposalController.ProposalPayload)  // fully signed call of an approved proposal
+	proposalGroup.POST("/execute", middlewares.CheckRole(consts.ADMIN_ROLE_OPERATOR), proposalController.ExecuteProposal) // record the executing transaction
 
 	userController := controllers.UserController{}
-	v2Group.POST("/user/login", userController.Login)                             // login
-	v2Group.POST("/user/logout", middlewares.CheckToken(), userController.Logout) // logout
+	v2Group.POST("/user/login", userController.Login)                                            // login
+	v2Group.POST("/user/refresh", userController.Refresh)                                        // rotate the refresh token
+	v2Group.POST("/user/logout", middlewares.CheckToken(), userController.Logout)                // logout of the current session
+	v2Group.POST("/user/logoutAll", middlewares.CheckToken(), userController.LogoutAll)          // logout of every session
+	v2Group.GET("/user/sessions", middlewares.CheckToken(), userController.Sessions)             // active sessions
+	v2Group.POST("/user/session/revoke", middlewares.CheckToken(), userController.RevokeSession) // logout of one session
+	v2Group.GET("/user/nonce", userController.Nonce)                                             // nonce of a sign-in with ethereum message
+	v2Group.POST("/user/siwe", userController.SiweLogin)                                         // sign-in with ethereum
+
+	// admin users, managed by multisig-admin
+	adminGroup := v2Group.Group("/admin", middlewares.CheckToken(), middlewares.CheckRole(consts.ADMIN_ROLE_MULTISIG_ADMIN))
+	adminGroup.GET("/users", userController.AdminUsers)                               // admin user list
+	adminGroup.POST("/user/create", userController.CreateAdminUser)                   // create admin user
+	adminGroup.POST("/user/update", userController.UpdateAdminUser)                   // change role or password
+	adminGroup.POST("/user/delete", userController.DeleteAdminUser)                   // delete admin user
+	adminGroup.GET("/user/sessions", userController.AdminUserSessions)                // active sessions of an admin user
+	adminGroup.POST("/multiSign/rollback", multiSignPoolController.RollbackMultiSign) // re-activate a multi-sign version
+
+	// explorer tables
+	studyController := controllers.StudyController{}
+	adminGroup.GET("/backfill/status", studyController.BackfillStatus) // progress of the block backfill
 
-	v2Group.GET("/getConfig", func(ctx *gin.Context) {
+	// the config holds the jwt secret, the signer private keys and the seeded admin password
+	v2Group.GET("/getConfig", middlewares.CheckToken(), middlewares.CheckRole(consts.ADMIN_ROLE_MULTISIG_ADMIN), func(ctx *gin.Context) {
 		ctx.JSON(200, config.Config)
 	})
 
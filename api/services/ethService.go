@@ -10,6 +10,7 @@ import (
 	"pledge-backend/api/models"
 	"pledge-backend/api/models/request"
 	"pledge-backend/api/models/response"
+	"pledge-backend/chainclient"
 	"pledge-backend/config"
 	"pledge-backend/contract/store"
 	"pledge-backend/db"
@@ -18,7 +19,6 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 	"gorm.io/gorm"
 )
@@ -31,24 +31,32 @@ func NewEthService() *EthService {
 }
 
 func (s *EthService) GetTxMsg(txHash string) (*models.Transaction, int) {
+	client, err := chainclient.GetStudyClient()
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return nil, statecode.CommonErrServerErr
+	}
+
 	// 查询数据库，如果数据库不存在交易信息，则从链上获取
+	// the transactions cached before the block hash was saved are fetched again
 	transaction := &models.Transaction{}
-	err := db.Mysql.Table(transaction.TableName()).Where("hash = ?", txHash).First(&transaction).Debug().Error
+	err = db.Mysql.Table(transaction.TableName()).Where("hash = ? and block_hash <> '' and canonical = ?", txHash, true).First(&transaction).Debug().Error
 	if err == nil {
-		return transaction, statecode.CommonSuccess
+		// a transaction of an unfinalized block is served only while its block is on the chain
+		canonical, err := s.isCanonical(client, transaction.BlockNumber, transaction.BlockHash)
+		if err != nil {
+			log.Logger.Error(err.Error())
+			return nil, statecode.CommonErrServerErr
+		}
+		if canonical {
+			return transaction, statecode.CommonSuccess
+		}
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		log.Logger.Error(err.Error())
 		return nil, statecode.CommonErrServerErr
 	}
 
 	// 从链上获取数据
-	client, err := ethclient.Dial(config.Config.TestNet.TestEthUrl)
-	if nil != err {
-		log.Logger.Error(err.Error())
-		return nil, statecode.CommonErrServerErr
-	}
-	defer client.Close()
-
 	tx, _, err := client.TransactionByHash(context.Background(), common.HexToHash(txHash))
 	if nil != err {
 		log.Logger.Error(err.Error())
@@ -61,7 +69,7 @@ func (s *EthService) GetTxMsg(txHash string) (*models.Transaction, int) {
 		log.Logger.Error(err.Error())
 		return nil, statecode.CommonErrServerErr
 	}
-	transaction = models.NewTransaction(tx, receipt.BlockNumber.Uint64())
+	transaction = models.NewTransaction(tx, receipt.BlockNumber.Uint64(), receipt.BlockHash.String())
 
 	// 封装对象，落库
 	_ = db.Mysql.Table(transaction.TableName()).Create(transaction)
@@ -79,24 +87,30 @@ func (s *EthService) GetTxMsg(txHash string) (*models.Transaction, int) {
 }
 
 func (s *EthService) GetReceipt(txHash string) (*models.Receipt, int) {
+	client, err := chainclient.GetStudyClient()
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return nil, statecode.CommonErrServerErr
+	}
+
 	// 查询数据库，如果数据库不存在交易信息，则从链上获取
 	receiptDO := &models.Receipt{}
-	err := db.Mysql.Table(receiptDO.TableName()).Where("transaction_hash = ?", txHash).First(&receiptDO).Debug().Error
+	err = db.Mysql.Table(receiptDO.TableName()).Where("transaction_hash = ? and canonical = ?", txHash, true).First(&receiptDO).Debug().Error
 	if err == nil {
-		return receiptDO, statecode.CommonSuccess
+		canonical, err := s.isCanonical(client, receiptDO.BlockNumber, receiptDO.BlockHash)
+		if err != nil {
+			log.Logger.Error(err.Error())
+			return nil, statecode.CommonErrServerErr
+		}
+		if canonical {
+			return receiptDO, statecode.CommonSuccess
+		}
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		log.Logger.Error(err.Error())
 		return nil, statecode.CommonErrServerErr
 	}
 
 	// 从链上获取数据
-	client, err := ethclient.Dial(config.Config.TestNet.TestEthUrl)
-	if nil != err {
-		log.Logger.Error(err.Error())
-		return nil, statecode.CommonErrServerErr
-	}
-	defer client.Close()
-
 	receipt, err := client.TransactionReceipt(context.Background(), common.HexToHash(txHash))
 	if nil != err {
 		log.Logger.Error(err.Error())
@@ -117,6 +131,12 @@ func (s *EthService) GetBlock(param *request.Block) (*response.Block, int) {
 	blockNum := param.BlockNum
 	blockDO := models.Block{}
 
+	client, err := chainclient.GetStudyClient()
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return nil, statecode.CommonErrServerErr
+	}
+
 	// 如果是head、finalize、safe节点，先尝试从Redis获取
 	if checkSpecialBlock(blockNum) {
 		key := consts.SPECIAL_BLOCK_KEY_PREFIX + blockNum.String()
@@ -137,14 +157,21 @@ func (s *EthService) GetBlock(param *request.Block) (*response.Block, int) {
 		// Redis中没有，那就从链上获取
 	} else {
 		// 从库里获取Block信息
-		err := db.Mysql.Table(blockDO.TableName()).Where("number = ?", blockNum.Uint64()).First(&blockDO).Debug().Error
-		// 如果err为nil，说明查询到了数据，直接返回即可
+		err := db.Mysql.Table(blockDO.TableName()).Where("number = ? and canonical = ?", blockNum.Uint64(), true).First(&blockDO).Debug().Error
+		// 如果err为nil，说明查询到了数据，区块未被重组时直接返回即可
 		if err == nil {
-			blockResp := response.NewBlock(&blockDO)
-			if param.Full {
-				s.GetTransaction(blockResp)
+			canonical, err := s.isCanonical(client, blockDO.Number, blockDO.Hash)
+			if err != nil {
+				log.Logger.Error(err.Error())
+				return nil, statecode.CommonErrServerErr
+			}
+			if canonical {
+				blockResp := response.NewBlock(&blockDO)
+				if param.Full {
+					s.GetTransaction(blockResp)
+				}
+				return blockResp, statecode.CommonSuccess
 			}
-			return blockResp, statecode.CommonSuccess
 		} else if !errors.Is(err, gorm.ErrRecordNotFound) { // sql报错，直接返回错误
 			log.Logger.Error(err.Error())
 			return nil, statecode.CommonErrServerErr
@@ -152,12 +179,6 @@ func (s *EthService) GetBlock(param *request.Block) (*response.Block, int) {
 	}
 
 	// 库里没有数据，从链上获取数据
-	client, err := ethclient.Dial(config.Config.TestNet.TestEthUrl)
-	if nil != err {
-		log.Logger.Error(err.Error())
-		return nil, statecode.CommonErrServerErr
-	}
-	defer client.Close()
 	block, err := client.BlockByNumber(context.Background(), blockNum)
 	if nil != err {
 		log.Logger.Error(err.Error())
@@ -167,13 +188,17 @@ func (s *EthService) GetBlock(param *request.Block) (*response.Block, int) {
 	// 落库
 	blockDO = models.Block{
 		Hash:         block.Hash().String(),
+		ParentHash:   block.ParentHash().String(),
 		Nonce:        block.Nonce(),
 		Number:       block.Number().Uint64(),
 		Time:         block.Time(),
 		Transactions: uint64(block.Transactions().Len()),
 	}
-	// 两边同时插入同一条数据，如果存在唯一索引（比如Number）可能会报错，但是肯定有一条正确是数据能落库，不需要处理这种异常
-	db.Mysql.Table(blockDO.TableName()).Create(&blockDO)
+	// 同一高度的其他区块标记为非规范区块，并发插入同一条数据的报错不需要处理
+	err = models.NewBlock().Save(&blockDO)
+	if err != nil {
+		log.Logger.Error(err.Error())
+	}
 	blockResp := response.NewBlock(&blockDO)
 
 	// 三个特殊区块需要实时存入Redis
@@ -185,11 +210,11 @@ func (s *EthService) GetBlock(param *request.Block) (*response.Block, int) {
 	if param.Full && blockResp.Transactions != 0 {
 		transactionRespList := make([]*models.Transaction, 0)
 		for _, tx := range block.Transactions() {
-			transactionDB := models.NewTransaction(tx, blockResp.Number)
+			transactionDB := models.NewTransaction(tx, blockResp.Number, blockResp.Hash)
 			transactionRespList = append(transactionRespList, transactionDB)
 		}
 		// 数据落库
-		err = (&models.Transaction{}).InsertFromBlock(transactionRespList, blockResp.Number)
+		err = (&models.Transaction{}).InsertFromBlock(transactionRespList, blockResp.Hash)
 		if err != nil {
 			log.Logger.Error(err.Error())
 			return nil, statecode.CommonErrServerErr
@@ -210,36 +235,35 @@ func (s *EthService) GetTransaction(blockResp *response.Block) int {
 	// 查询库中数据条数是否匹配
 	var count int64 = 0
 	transactionRespList := make([]*models.Transaction, 0)
-	err := db.Mysql.Table("transaction").Where("block_number = ?", blockResp.Number).Count(&count).Debug().Error
+	err := db.Mysql.Table("transaction").Where("block_hash = ?", blockResp.Hash).Count(&count).Debug().Error
 	if err != nil {
 		log.Logger.Error(err.Error())
 		return statecode.CommonErrServerErr
 	}
 	// 数据库已有交易信息，且数量相等，直接返回
 	if count == int64(blockResp.Transactions) {
-		err := db.Mysql.Table("transaction").Where("block_number = ?", blockResp.Number).Find(&transactionRespList).Debug().Error
+		err := db.Mysql.Table("transaction").Where("block_hash = ?", blockResp.Hash).Find(&transactionRespList).Debug().Error
 		if err != nil {
 			log.Logger.Error(err.Error())
 			return statecode.CommonErrServerErr
 		}
 	} else { // 库中没有数据，或者数据条数不对，从链上获取
-		client, err := ethclient.Dial(config.Config.TestNet.TestEthUrl)
+		client, err := chainclient.GetStudyClient()
 		if nil != err {
 			log.Logger.Error(err.Error())
 			return statecode.CommonErrServerErr
 		}
-		defer client.Close()
-		block, err := client.BlockByNumber(context.Background(), big.NewInt(int64(blockResp.Number)))
+		block, err := client.BlockByHash(context.Background(), common.HexToHash(blockResp.Hash))
 		if err != nil {
 			log.Logger.Error(err.Error())
 			return statecode.CommonErrServerErr
 		}
 		for _, tx := range block.Transactions() {
-			transactionDB := models.NewTransaction(tx, blockResp.Number)
+			transactionDB := models.NewTransaction(tx, blockResp.Number, blockResp.Hash)
 			transactionRespList = append(transactionRespList, transactionDB)
 		}
 		// 数据落库，先删后插
-		err = (&models.Transaction{}).InsertFromBlock(transactionRespList, blockResp.Number)
+		err = (&models.Transaction{}).InsertFromBlock(transactionRespList, blockResp.Hash)
 		if err != nil {
 			log.Logger.Error(err.Error())
 			return statecode.CommonErrServerErr
@@ -252,15 +276,14 @@ func (s *EthService) GetTransaction(blockResp *response.Block) int {
 
 func (s *EthService) SetItem(key string, value string) (interface{}, int) {
 	// 建立连接
-	client, err := ethclient.Dial(config.Config.TestNet.TestEthUrl)
+	client, err := chainclient.GetStudyClient()
 	if nil != err {
 		log.Logger.Error(err.Error())
 		return nil, statecode.CommonErrServerErr
 	}
-	defer client.Close()
 
 	// 生成合约实例
-	storeAddress := common.HexToAddress(config.Config.TestNet.StoreAddress)
+	storeAddress := common.HexToAddress(config.Config.Study.StoreAddress)
 	storeInstance, err := store.NewStore(storeAddress, client)
 	if nil != err {
 		log.Logger.Error(err.Error())
@@ -268,7 +291,7 @@ func (s *EthService) SetItem(key string, value string) (interface{}, int) {
 	}
 
 	// 获取私钥
-	privateKey, err := crypto.HexToECDSA(config.Config.TestNet.PrivateKey)
+	privateKey, err := crypto.HexToECDSA(config.Config.Study.PrivateKey)
 	if nil != err {
 		log.Logger.Error(err.Error())
 		return nil, statecode.CommonErrServerErr
@@ -316,6 +339,73 @@ func (s *EthService) SetItem(key string, value string) (interface{}, int) {
 	return res, statecode.CommonSuccess
 }
 
+// BackfillStatus progress of the backfill of the configured block range
+func (s *EthService) BackfillStatus(result *response.BackfillStatus) int {
+	conf := config.Config.Backfill
+	*result = response.BackfillStatus{
+		Enabled:    conf.Enabled,
+		StartBlock: conf.StartBlock,
+		EndBlock:   conf.EndBlock,
+		NextBlock:  conf.StartBlock,
+	}
+
+	backfill, err := models.NewBlockBackfill().GetBackfill(conf.StartBlock, conf.EndBlock)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return statecode.CommonSuccess
+		}
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	result.NextBlock = backfill.NextBlock
+	result.TargetBlock = backfill.TargetBlock
+	if backfill.TargetBlock >= backfill.NextBlock {
+		result.Remaining = backfill.TargetBlock - backfill.NextBlock + 1
+	}
+	result.Status = backfill.Status
+	result.Error = backfill.Error
+	result.UpdatedAt = backfill.UpdatedAt
+	return statecode.CommonSuccess
+}
+
+// finalizedNumber number of the finalized head polled by the schedule, read from the chain if it is not cached
+func (s *EthService) finalizedNumber(client *chainclient.Client) (uint64, error) {
+	finalizedNum := big.NewInt(rpc.FinalizedBlockNumber.Int64())
+	blockByte, _ := db.RedisGet(consts.SPECIAL_BLOCK_KEY_PREFIX + finalizedNum.String())
+	if len(blockByte) > 0 {
+		blockResp := response.Block{}
+		if json.Unmarshal(blockByte, &blockResp) == nil {
+			return blockResp.Number, nil
+		}
+	}
+	header, err := client.HeaderByNumber(context.Background(), finalizedNum)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
+
+// isCanonical whether a cached block is still on the chain, a block behind the finalized head can not be reorged any more.
+// A reorged block is marked as orphaned with its transactions and receipts so they are fetched again.
+func (s *EthService) isCanonical(client *chainclient.Client, number uint64, hash string) (bool, error) {
+	finalized, err := s.finalizedNumber(client)
+	if err != nil {
+		return false, err
+	}
+	if number <= finalized {
+		return true, nil
+	}
+	header, err := client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(number))
+	if err != nil {
+		return false, err
+	}
+	if header.Hash().String() == hash {
+		return true, nil
+	}
+	log.Logger.Sugar().Info("block reorged ", number, " ", hash, " ", header.Hash().String())
+	return false, models.NewBlock().MarkOrphaned(hash)
+}
+
 func checkSpecialBlock(blockNum *big.Int) bool {
 	return blockNum == nil || blockNum.Int64() == rpc.LatestBlockNumber.Int64() ||
 		blockNum.Int64() == rpc.FinalizedBlockNumber.Int64() || blockNum.Int64() == rpc.SafeBlockNumber.Int64()
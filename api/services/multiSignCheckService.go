@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
+	"pledge-backend/api/models/request"
+	"pledge-backend/api/models/response"
+	"pledge-backend/chainclient"
+	"pledge-backend/utils"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// CheckMultiSign verify a posted multi-sign against the chain before it is saved:
+// the addresses are checksummed, sp / jp are the tokens of one pool and the hashes are their mined deployments.
+// The failed fields are returned, err is only set if the chain or the db could not be read.
+func (c *MutiSignService) CheckMultiSign(mutiSign *request.SetMultiSign) ([]response.MultiSignCheckErr, error) {
+	checkErrs := make([]response.MultiSignCheckErr, 0)
+	addErr := func(field, value string, code int) {
+		checkErrs = append(checkErrs, response.MultiSignCheckErr{Field: field, Value: value, Code: code})
+	}
+
+	spValid := isChecksumAddress(mutiSign.SpAddress)
+	if !spValid {
+		addErr("sp_address", mutiSign.SpAddress, statecode.MultiSignAddressErr)
+	}
+	jpValid := isChecksumAddress(mutiSign.JpAddress)
+	if !jpValid {
+		addErr("jp_address", mutiSign.JpAddress, statecode.MultiSignAddressErr)
+	}
+	for _, account := range mutiSign.MultiSignAccount {
+		if !isChecksumAddress(account) {
+			addErr("multi_sign_account", account, statecode.MultiSignAddressErr)
+		}
+	}
+	if !spValid || !jpValid {
+		return checkErrs, nil
+	}
+
+	var pools []models.PoolBaseInfoRes
+	err := models.NewPoolBases().PoolBaseInfo(mutiSign.ChainId, &pools)
+	if err != nil {
+		return nil, err
+	}
+	spFound, jpFound, pairFound := false, false, false
+	for _, pool := range pools {
+		spMatch := strings.EqualFold(pool.PoolData.SpCoin, mutiSign.SpAddress)
+		jpMatch := strings.EqualFold(pool.PoolData.JpCoin, mutiSign.JpAddress)
+		spFound = spFound || spMatch
+		jpFound = jpFound || jpMatch
+		pairFound = pairFound || (spMatch && jpMatch)
+	}
+	if !spFound {
+		addErr("sp_address", mutiSign.SpAddress, statecode.MultiSignTokenNotInPool)
+	}
+	if !jpFound || (spFound && !pairFound) {
+		addErr("jp_address", mutiSign.JpAddress, statecode.MultiSignTokenNotInPool)
+	}
+
+	ethereumConn, err := chainclient.GetClient(utils.IntToString(mutiSign.ChainId))
+	if err != nil {
+		return nil, err
+	}
+	for _, deploy := range []struct {
+		field, hash, address string
+	}{
+		{"spHash", mutiSign.SpHash, mutiSign.SpAddress},
+		{"jpHash", mutiSign.JpHash, mutiSign.JpAddress},
+	} {
+		code, err := checkDeployTx(ethereumConn, deploy.hash, deploy.address)
+		if err != nil {
+			return nil, err
+		}
+		if code != statecode.CommonSuccess {
+			addErr(deploy.field, deploy.hash, code)
+		}
+	}
+	return checkErrs, nil
+}
+
+// checkDeployTx whether hash is a mined transaction that created the contract at address
+func checkDeployTx(ethereumConn *chainclient.Client, hash, address string) (int, error) {
+	hashBytes, err := hexutil.Decode(hash)
+	if err != nil || len(hashBytes) != common.HashLength {
+		return statecode.MultiSignHashErr, nil
+	}
+	receipt, err := ethereumConn.TransactionReceipt(context.Background(), common.BytesToHash(hashBytes))
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return statecode.MultiSignTxNotMined, nil
+		}
+		return statecode.CommonErrServerErr, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return statecode.MultiSignTxFailed, nil
+	}
+	if receipt.ContractAddress != common.HexToAddress(address) {
+		return statecode.MultiSignContractMismatch, nil
+	}
+	return statecode.CommonSuccess, nil
+}
+
+// isChecksumAddress a 0x address in its EIP-55 mixed case form
+func isChecksumAddress(address string) bool {
+	return common.IsHexAddress(address) && common.HexToAddress(address).Hex() == address
+}
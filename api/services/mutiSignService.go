@@ -2,10 +2,15 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"pledge-backend/api/common/statecode"
 	"pledge-backend/api/models"
 	"pledge-backend/api/models/request"
 	"pledge-backend/api/models/response"
+	"pledge-backend/log"
+	"strings"
+
+	"gorm.io/gorm"
 )
 
 type MutiSignService struct{}
@@ -15,12 +20,23 @@ func NewMutiSign() *MutiSignService {
 }
 
 // SetMultiSign Set Multi-Sign
-func (c *MutiSignService) SetMultiSign(mutiSign *request.SetMultiSign) (int, error) {
+func (c *MutiSignService) SetMultiSign(mutiSign *request.SetMultiSign, operator string, checkErrs *[]response.MultiSignCheckErr) (int, error) {
+	//chain check
+	errs, err := c.CheckMultiSign(mutiSign)
+	if err != nil {
+		return statecode.CommonErrServerErr, err
+	}
+	if len(errs) > 0 {
+		*checkErrs = errs
+		return errs[0].Code, errors.New("multi-sign check failed " + errs[0].Field + " " + errs[0].Value)
+	}
+
 	//db set
-	err := models.NewMultiSign().Set(mutiSign)
+	err = models.NewMultiSign().Set(mutiSign, operator)
 	if err != nil {
 		return statecode.CommonErrServerErr, err
 	}
+	c.revokeRemovedWallets()
 	return statecode.CommonSuccess, nil
 }
 
@@ -32,17 +48,144 @@ func (c *MutiSignService) GetMultiSign(mutiSign *response.MultiSign, chainId int
 	if err != nil {
 		return statecode.CommonErrServerErr, err
 	}
+	*mutiSign = toMultiSign(multiSignModel)
+	return statecode.CommonSuccess, nil
+}
+
+// MultiSignHistory every version of a chain, newest first
+func (c *MutiSignService) MultiSignHistory(result *[]response.MultiSignVersion, chainId int) (int, error) {
+	multiSigns, err := models.NewMultiSign().History(chainId)
+	if err != nil {
+		return statecode.CommonErrServerErr, err
+	}
+	versions := make([]response.MultiSignVersion, 0, len(multiSigns))
+	for i := range multiSigns {
+		versions = append(versions, toMultiSignVersion(&multiSigns[i]))
+	}
+	*result = versions
+	return statecode.CommonSuccess, nil
+}
+
+// MultiSignDiff field changes and multi-sign account changes from one version to another
+func (c *MutiSignService) MultiSignDiff(req *request.MultiSignDiff, result *response.MultiSignDiff) (int, error) {
+	from, errCode, err := c.getVersion(req.ChainId, req.From)
+	if errCode != statecode.CommonSuccess {
+		return errCode, err
+	}
+	to, errCode, err := c.getVersion(req.ChainId, req.To)
+	if errCode != statecode.CommonSuccess {
+		return errCode, err
+	}
+	fromMultiSign, toMultiSign := toMultiSign(&from), toMultiSign(&to)
+
+	result.ChainId = req.ChainId
+	result.From = req.From
+	result.To = req.To
+	result.Changes = make([]response.MultiSignFieldChange, 0)
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"sp_name", fromMultiSign.SpName, toMultiSign.SpName},
+		{"_spToken", fromMultiSign.SpToken, toMultiSign.SpToken},
+		{"jp_name", fromMultiSign.JpName, toMultiSign.JpName},
+		{"_jpToken", fromMultiSign.JpToken, toMultiSign.JpToken},
+		{"sp_address", fromMultiSign.SpAddress, toMultiSign.SpAddress},
+		{"jp_address", fromMultiSign.JpAddress, toMultiSign.JpAddress},
+		{"spHash", fromMultiSign.SpHash, toMultiSign.SpHash},
+		{"jpHash", fromMultiSign.JpHash, toMultiSign.JpHash},
+	}
+	for _, field := range fields {
+		if field.from != field.to {
+			result.Changes = append(result.Changes, response.MultiSignFieldChange{Field: field.name, From: field.from, To: field.to})
+		}
+	}
+	result.AccountsAdded = accountsNotIn(toMultiSign.MultiSignAccount, fromMultiSign.MultiSignAccount)
+	result.AccountsRemoved = accountsNotIn(fromMultiSign.MultiSignAccount, toMultiSign.MultiSignAccount)
+	return statecode.CommonSuccess, nil
+}
+
+// RollbackMultiSign make a prior version the active one again
+func (c *MutiSignService) RollbackMultiSign(req *request.RollbackMultiSign, operator string, result *response.MultiSignVersion) (int, error) {
+	target, errCode, err := c.getVersion(req.ChainId, req.Version)
+	if errCode != statecode.CommonSuccess {
+		return errCode, err
+	}
+	if target.IsActive {
+		return statecode.MultiSignVersionActive, errors.New("multi-sign version is already active")
+	}
+
+	multiSign, err := models.NewMultiSign().Rollback(req.ChainId, req.Version, operator)
+	if err != nil {
+		return statecode.CommonErrServerErr, err
+	}
+	log.Logger.Sugar().Info("multi-sign rollback ", req.ChainId, " to version ", req.Version, " by ", operator, " as version ", multiSign.Version)
+	c.revokeRemovedWallets()
+	*result = toMultiSignVersion(&multiSign)
+	return statecode.CommonSuccess, nil
+}
+
+// revokeRemovedWallets the new version is saved already, a failed revoke is logged and the wallet is rejected at its next refresh
+func (c *MutiSignService) revokeRemovedWallets() {
+	err := NewUser().RevokeRemovedWallets()
+	if err != nil {
+		log.Logger.Sugar().Error("multi-sign revoke removed wallets err ", err)
+	}
+}
+
+func (c *MutiSignService) getVersion(chainId, version int) (models.MultiSign, int, error) {
+	multiSign, err := models.NewMultiSign().GetVersion(chainId, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return multiSign, statecode.MultiSignVersionNotFound, err
+		}
+		return multiSign, statecode.CommonErrServerErr, err
+	}
+	return multiSign, statecode.CommonSuccess, nil
+}
+
+func toMultiSign(multiSignModel *models.MultiSign) response.MultiSign {
 	var multiSignAccount []string
 	_ = json.Unmarshal([]byte(multiSignModel.MultiSignAccount), &multiSignAccount)
 
-	mutiSign.SpName = multiSignModel.SpName
-	mutiSign.SpToken = multiSignModel.SpToken
-	mutiSign.JpName = multiSignModel.JpName
-	mutiSign.JpToken = multiSignModel.JpToken
-	mutiSign.SpAddress = multiSignModel.SpAddress
-	mutiSign.JpAddress = multiSignModel.JpAddress
-	mutiSign.SpHash = multiSignModel.SpHash
-	mutiSign.JpHash = multiSignModel.JpHash
-	mutiSign.MultiSignAccount = multiSignAccount
-	return statecode.CommonSuccess, nil
+	return response.MultiSign{
+		SpName:           multiSignModel.SpName,
+		SpToken:          multiSignModel.SpToken,
+		JpName:           multiSignModel.JpName,
+		JpToken:          multiSignModel.JpToken,
+		SpAddress:        multiSignModel.SpAddress,
+		JpAddress:        multiSignModel.JpAddress,
+		SpHash:           multiSignModel.SpHash,
+		JpHash:           multiSignModel.JpHash,
+		MultiSignAccount: multiSignAccount,
+	}
+}
+
+func toMultiSignVersion(multiSignModel *models.MultiSign) response.MultiSignVersion {
+	return response.MultiSignVersion{
+		MultiSign:    toMultiSign(multiSignModel),
+		Version:      multiSignModel.Version,
+		IsActive:     multiSignModel.IsActive,
+		RollbackFrom: multiSignModel.RollbackFrom,
+		CreatedBy:    multiSignModel.CreatedBy,
+		CreatedAt:    multiSignModel.CreatedAt,
+	}
+}
+
+// accountsNotIn accounts of a that are not in b, addresses compare case-insensitively
+func accountsNotIn(a, b []string) []string {
+	result := make([]string, 0)
+	for _, account := range a {
+		found := false
+		for _, other := range b {
+			if strings.EqualFold(account, other) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, account)
+		}
+	}
+	return result
 }
@@ -0,0 +1,65 @@
+package services
+
+import (
+	consts "pledge-backend/api/common"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
+	"pledge-backend/api/models/request"
+	"pledge-backend/api/models/response"
+	"pledge-backend/log"
+)
+
+type PoolHistoryService struct{}
+
+func NewPoolHistory() *PoolHistoryService {
+	return &PoolHistoryService{}
+}
+
+// PoolHistory Bucket the snapshots of a pool, each point holds the last values known at the end of its bucket
+func (s *PoolHistoryService) PoolHistory(req *request.PoolHistory, result *response.PoolHistory) int {
+
+	// the snapshot before the range carries the values into the first buckets
+	last, hasLast, err := models.NewPoolSnapshot().LastPoolSnapshot(req.ChainId, req.PoolId, req.From)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	snapshots, err := models.NewPoolSnapshot().PoolHistory(req.ChainId, req.PoolId, req.From, req.To)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	interval := consts.POOL_HISTORY_INTERVALS[req.Interval]
+	result.ChainId = req.ChainId
+	result.PoolId = req.PoolId
+	result.Interval = req.Interval
+	result.Points = make([]response.PoolHistoryPoint, 0)
+
+	i := 0
+	for bucket := req.From - req.From%interval; bucket <= req.To; bucket += interval {
+		for i < len(snapshots) && snapshots[i].SnapshotTime < bucket+interval {
+			last, hasLast = snapshots[i], true
+			i++
+		}
+		if !hasLast {
+			continue
+		}
+		result.Points = append(result.Points, response.PoolHistoryPoint{
+			Time:                   bucket,
+			State:                  last.State,
+			InterestRate:           last.InterestRate,
+			LendSupply:             last.LendSupply,
+			BorrowSupply:           last.BorrowSupply,
+			FinishAmountBorrow:     last.FinishAmountBorrow,
+			FinishAmountLend:       last.FinishAmountLend,
+			LiquidationAmounBorrow: last.LiquidationAmounBorrow,
+			LiquidationAmounLend:   last.LiquidationAmounLend,
+			SettleAmountBorrow:     last.SettleAmountBorrow,
+			SettleAmountLend:       last.SettleAmountLend,
+		})
+	}
+
+	return statecode.CommonSuccess
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"math"
+	"pledge-backend/api/models"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// rateDecimal interestRate, martgageRate and oracle prices are scaled by 1e8
+	rateDecimal = decimal.NewFromInt(100000000)
+	secondsYear = decimal.NewFromInt(365 * 24 * 3600)
+	secondsDay  = decimal.NewFromInt(24 * 3600)
+)
+
+type PoolMetricsService struct{}
+
+func NewPoolMetrics() *PoolMetricsService {
+	return &PoolMetricsService{}
+}
+
+// PoolMetricsParam raw pool fields the metrics are computed from
+type PoolMetricsParam struct {
+	LendSupply   string
+	BorrowSupply string
+	InterestRate string
+	MartgageRate string
+	SettleTime   string
+	EndTime      string
+	LendToken    string
+	BorrowToken  string
+}
+
+// TokenMap token_info of a chain keyed by lower case token address
+func (s *PoolMetricsService) TokenMap(chainId int) (map[string]models.TokenValue, error) {
+	err, tokens := models.NewTokenInfo().GetTokenValues(chainId)
+	if err != nil {
+		return nil, err
+	}
+	tokenMap := make(map[string]models.TokenValue, len(tokens))
+	for _, t := range tokens {
+		tokenMap[strings.ToLower(t.Token)] = t
+	}
+	return tokenMap, nil
+}
+
+// PoolMetrics Compute utilization, TVL, collateralization and interest of a pool
+func (s *PoolMetricsService) PoolMetrics(param *PoolMetricsParam, tokenMap map[string]models.TokenValue) *models.PoolMetrics {
+	metrics := &models.PoolMetrics{}
+
+	interestRate, err := decimal.NewFromString(param.InterestRate)
+	if err == nil {
+		metrics.InterestRate = interestRate.Div(rateDecimal).StringFixed(8)
+		settleTime, err1 := decimal.NewFromString(param.SettleTime)
+		endTime, err2 := decimal.NewFromString(param.EndTime)
+		if err1 == nil && err2 == nil && endTime.GreaterThan(settleTime) {
+			duration := endTime.Sub(settleTime)
+			// same time ratio as the interest of the contract: rate * duration / 365 days
+			periodInterest := interestRate.Div(rateDecimal).Mul(duration).Div(secondsYear)
+			periods, _ := secondsYear.Div(duration).Float64()
+			period, _ := periodInterest.Float64()
+			metrics.DurationDays = duration.Div(secondsDay).StringFixed(2)
+			metrics.PeriodInterest = periodInterest.StringFixed(8)
+			metrics.EffectiveApy = decimal.NewFromFloat(math.Pow(1+period, periods) - 1).StringFixed(8)
+		}
+	}
+
+	lendUsd, lendOk := s.usdValue(param.LendSupply, tokenMap[strings.ToLower(param.LendToken)])
+	borrowUsd, borrowOk := s.usdValue(param.BorrowSupply, tokenMap[strings.ToLower(param.BorrowToken)])
+	if lendOk {
+		metrics.LendTvlUsd = lendUsd.StringFixed(2)
+	}
+	if borrowOk {
+		metrics.BorrowTvlUsd = borrowUsd.StringFixed(2)
+	}
+	if !lendOk || !borrowOk {
+		return metrics
+	}
+	metrics.TvlUsd = lendUsd.Add(borrowUsd).StringFixed(2)
+
+	if lendUsd.IsZero() {
+		return metrics
+	}
+	metrics.CollateralizationRatio = borrowUsd.Div(lendUsd).StringFixed(8)
+
+	martgageRate, err := decimal.NewFromString(param.MartgageRate)
+	if err != nil || martgageRate.IsZero() {
+		return metrics
+	}
+	// settle matches at most borrow value / martgageRate of the lend supply
+	matchable := borrowUsd.Div(martgageRate.Div(rateDecimal))
+	utilization := decimal.Min(matchable.Div(lendUsd), decimal.NewFromInt(1))
+	metrics.Utilization = utilization.StringFixed(8)
+
+	return metrics
+}
+
+// usdValue token amount in USD, false if the token has no price
+func (s *PoolMetricsService) usdValue(amount string, token models.TokenValue) (decimal.Decimal, bool) {
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return decimal.Zero, false
+	}
+	price, err := decimal.NewFromString(token.Price)
+	if err != nil || price.IsZero() || token.Decimals <= 0 {
+		return decimal.Zero, false
+	}
+	return value.Shift(-int32(token.Decimals)).Mul(price).Div(rateDecimal), true
+}
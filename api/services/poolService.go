@@ -19,6 +19,24 @@ func (s *poolService) PoolBaseInfo(chainId int, result *[]models.PoolBaseInfoRes
 		log.Logger.Error(err.Error())
 		return statecode.CommonErrServerErr
 	}
+
+	tokenMap, err := NewPoolMetrics().TokenMap(chainId)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	for i, p := range *result {
+		(*result)[i].PoolData.Metrics = NewPoolMetrics().PoolMetrics(&PoolMetricsParam{
+			LendSupply:   p.PoolData.LendSupply,
+			BorrowSupply: p.PoolData.BorrowSupply,
+			InterestRate: p.PoolData.InterestRate,
+			MartgageRate: p.PoolData.MartgageRate,
+			SettleTime:   p.PoolData.SettleTime,
+			EndTime:      p.PoolData.EndTime,
+			LendToken:    p.PoolData.LendToken,
+			BorrowToken:  p.PoolData.BorrowToken,
+		}, tokenMap)
+	}
 	return statecode.CommonSuccess
 }
 
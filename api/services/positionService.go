@@ -0,0 +1,151 @@
+package services
+
+import (
+	"math/big"
+	consts "pledge-backend/api/common"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
+	"pledge-backend/api/models/request"
+	"pledge-backend/api/models/response"
+	"pledge-backend/chainclient"
+	"pledge-backend/config"
+	"pledge-backend/contract/bindings"
+	"pledge-backend/log"
+	"pledge-backend/utils"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+type PositionService struct{}
+
+func NewPosition() *PositionService {
+	return &PositionService{}
+}
+
+// userInfo the common output of userLendInfo and userBorrowInfo
+type userInfo struct {
+	StakeAmount  *big.Int
+	RefundAmount *big.Int
+	HasNoRefund  bool
+	HasNoClaim   bool
+}
+
+// UserPositions Get the lend and borrow positions of a wallet in every pool of a chain
+func (s *PositionService) UserPositions(req *request.Positions, result *response.UserPositions) int {
+
+	chain, ok := config.GetChain(utils.IntToString(req.ChainId))
+	if !ok {
+		return statecode.ChainIdErr
+	}
+
+	err, pools := models.NewPool().ChainPools(req.ChainId)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	err, tokens := models.NewTokenInfo().GetTokenValues(req.ChainId)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	tokenMap := make(map[string]models.TokenValue, len(tokens))
+	for _, t := range tokens {
+		tokenMap[t.Token] = t
+	}
+
+	var poolData []models.PoolDataInfoRes
+	err = models.NewPoolData().PoolDataInfo(req.ChainId, &poolData)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	poolDataMap := make(map[int]models.PoolData, len(poolData))
+	for _, d := range poolData {
+		poolDataMap[d.PoolData.PoolID] = d.PoolData
+	}
+
+	ethereumConn, err := chainclient.GetClient(chain.ChainId)
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	pledgePool, err := bindings.NewPledgePoolTokenCaller(common.HexToAddress(chain.PledgePoolToken), ethereumConn)
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	user := common.HexToAddress(req.Address)
+	result.Address = user.String()
+	result.ChainId = req.ChainId
+	result.Positions = make([]response.PoolPosition, 0)
+
+	for _, p := range pools {
+		pid := big.NewInt(int64(p.PoolId - 1)) // pool_id in poolbases starts from 1
+
+		lendInfo, err := pledgePool.UserLendInfo(&bind.CallOpts{}, user, pid)
+		if err != nil {
+			log.Logger.Sugar().Error("UserPositions UserLendInfo err ", p.PoolId, err)
+			return statecode.CommonErrServerErr
+		}
+		borrowInfo, err := pledgePool.UserBorrowInfo(&bind.CallOpts{}, user, pid)
+		if err != nil {
+			log.Logger.Sugar().Error("UserPositions UserBorrowInfo err ", p.PoolId, err)
+			return statecode.CommonErrServerErr
+		}
+
+		data := poolDataMap[p.PoolId]
+		position := response.PoolPosition{
+			PoolId: p.PoolId,
+			State:  p.State,
+		}
+		if lendInfo.StakeAmount.Sign() > 0 {
+			position.Lend = s.positionSide(userInfo(lendInfo), p.LendToken, tokenMap[p.LendToken], p.State, p.LendSupply, data.SettleAmountLend)
+		}
+		if borrowInfo.StakeAmount.Sign() > 0 {
+			position.Borrow = s.positionSide(userInfo(borrowInfo), p.BorrowToken, tokenMap[p.BorrowToken], p.State, p.BorrowSupply, data.SettleAmountBorrow)
+		}
+		if position.Lend == nil && position.Borrow == nil {
+			continue
+		}
+		result.Positions = append(result.Positions, position)
+	}
+
+	return statecode.CommonSuccess
+}
+
+// positionSide build one side of a position and derive the actions allowed by the pool state
+func (s *PositionService) positionSide(info userInfo, tokenAddress string, token models.TokenValue, state, supply, settleAmount string) *response.PositionSide {
+	actions := make([]string, 0)
+	switch state {
+	case consts.POOL_STATE_EXECUTION, consts.POOL_STATE_FINISH, consts.POOL_STATE_LIQUIDATION:
+		if !info.HasNoClaim {
+			actions = append(actions, consts.POSITION_ACTION_CLAIM)
+		}
+		// only the part of the supply that was not matched on settle can be refunded
+		supplyDeci, _ := decimal.NewFromString(supply)
+		settleDeci, _ := decimal.NewFromString(settleAmount)
+		if !info.HasNoRefund && supplyDeci.GreaterThan(settleDeci) {
+			actions = append(actions, consts.POSITION_ACTION_REFUND)
+		}
+	case consts.POOL_STATE_UNDONE:
+		if !info.HasNoRefund {
+			actions = append(actions, consts.POSITION_ACTION_EMERGENCY_WITHDRAW)
+		}
+	}
+
+	return &response.PositionSide{
+		Token:        tokenAddress,
+		TokenSymbol:  token.Symbol,
+		TokenPrice:   token.Price,
+		StakeAmount:  info.StakeAmount.String(),
+		RefundAmount: info.RefundAmount.String(),
+		HasNoRefund:  info.HasNoRefund,
+		HasNoClaim:   info.HasNoClaim,
+		Actions:      actions,
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	consts "pledge-backend/api/common"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
+	"pledge-backend/api/models/kucoin"
+	"pledge-backend/api/models/request"
+	"pledge-backend/api/models/response"
+	"pledge-backend/config"
+	"pledge-backend/db"
+	"pledge-backend/log"
+	"pledge-backend/pubsub"
+
+	"github.com/shopspring/decimal"
+)
+
+type PriceService struct{}
+
+func NewPrice() *PriceService {
+	return &PriceService{}
+}
+
+// PriceStatus Last plgr price stored by the producer, whether it is stale and the state of its ku-coin connection
+func (s *PriceService) PriceStatus(result *response.PriceStatus) int {
+	price, err := db.RedisGetString(kucoin.PriceKey)
+	if err != nil {
+		price = kucoin.PlgrPrice
+	}
+	updatedAt := kucoin.GetPriceUpdatedAt()
+
+	result.Symbol = pubsub.MarketPriceSymbol
+	result.Price = price
+	result.UpdatedAt = updatedAt
+	result.Stale = kucoin.IsPriceStale(updatedAt)
+	result.StaleThreshold = config.Config.Kucoin.StaleThreshold
+	result.Producer = kucoin.GetProducerStatus()
+	return statecode.CommonSuccess
+}
+
+// PriceHistory Bucket the price rows of a token into OHLC candles, a bucket without rows repeats the last close
+func (s *PriceService) PriceHistory(req *request.PriceHistory, result *response.PriceHistory) int {
+
+	// the row before the range gives the price the first buckets start from
+	last, hasLast, err := models.NewTokenPrice().LastTokenPrice(req.ChainId, req.Token, req.From)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	lastClose := last.Close
+
+	prices, err := models.NewTokenPrice().PriceHistory(req.ChainId, req.Token, req.From, req.To)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	result.ChainId = req.ChainId
+	result.Token = req.Token
+	result.Interval = req.Interval
+	result.Candles = bucketCandles(prices, lastClose, hasLast, req.From, req.To, consts.PRICE_HISTORY_INTERVALS[req.Interval])
+
+	return statecode.CommonSuccess
+}
+
+// bucketCandles merge price rows ordered by time into candles of interval seconds from the bucket of from up to to,
+// lastClose is the close before the first row, buckets before any price are left out
+func bucketCandles(prices []models.TokenPrice, lastClose string, hasLast bool, from, to, interval int64) []response.PriceCandle {
+	candles := make([]response.PriceCandle, 0)
+	i := 0
+	for bucket := from - from%interval; bucket <= to; bucket += interval {
+		if i >= len(prices) || prices[i].PriceTime >= bucket+interval {
+			if !hasLast {
+				continue
+			}
+			candles = append(candles, response.PriceCandle{
+				Time: bucket, Open: lastClose, High: lastClose, Low: lastClose, Close: lastClose,
+			})
+			continue
+		}
+
+		candle := response.PriceCandle{Time: bucket, Open: prices[i].Open, High: prices[i].High, Low: prices[i].Low}
+		high, _ := decimal.NewFromString(prices[i].High)
+		low, _ := decimal.NewFromString(prices[i].Low)
+		for ; i < len(prices) && prices[i].PriceTime < bucket+interval; i++ {
+			rowHigh, _ := decimal.NewFromString(prices[i].High)
+			if rowHigh.GreaterThan(high) {
+				high, candle.High = rowHigh, prices[i].High
+			}
+			rowLow, _ := decimal.NewFromString(prices[i].Low)
+			if rowLow.LessThan(low) {
+				low, candle.Low = rowLow, prices[i].Low
+			}
+			candle.Close = prices[i].Close
+		}
+		candles = append(candles, candle)
+		lastClose, hasLast = candle.Close, true
+	}
+	return candles
+}
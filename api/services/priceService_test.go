@@ -0,0 +1,79 @@
+package services
+
+import (
+	"pledge-backend/api/models"
+	"pledge-backend/api/models/response"
+	"reflect"
+	"testing"
+)
+
+func TestBucketCandles(t *testing.T) {
+	row := func(priceTime int64, open, high, low, close string) models.TokenPrice {
+		return models.TokenPrice{PriceTime: priceTime, Open: open, High: high, Low: low, Close: close}
+	}
+	flat := func(bucket int64, price string) response.PriceCandle {
+		return response.PriceCandle{Time: bucket, Open: price, High: price, Low: price, Close: price}
+	}
+	tests := []struct {
+		name      string
+		prices    []models.TokenPrice
+		lastClose string
+		hasLast   bool
+		from, to  int64
+		want      []response.PriceCandle
+	}{
+		{
+			name: "no price at all", from: 3600, to: 10800,
+			want: []response.PriceCandle{},
+		},
+		{
+			name: "rows merged per bucket", from: 3600, to: 7199,
+			prices: []models.TokenPrice{
+				row(3600, "10", "12", "9", "11"),
+				row(5400, "11", "15", "10", "14"),
+			},
+			want: []response.PriceCandle{{Time: 3600, Open: "10", High: "15", Low: "9", Close: "14"}},
+		},
+		{
+			name: "from inside a bucket starts at the bucket", from: 5000, to: 7199,
+			prices: []models.TokenPrice{row(5400, "11", "12", "10", "12")},
+			want:   []response.PriceCandle{{Time: 3600, Open: "11", High: "12", Low: "10", Close: "12"}},
+		},
+		{
+			name: "a gap repeats the last close", from: 3600, to: 14400,
+			prices: []models.TokenPrice{
+				row(3600, "10", "12", "9", "11"),
+				row(14400, "20", "21", "19", "20"),
+			},
+			want: []response.PriceCandle{
+				{Time: 3600, Open: "10", High: "12", Low: "9", Close: "11"},
+				flat(7200, "11"),
+				flat(10800, "11"),
+				{Time: 14400, Open: "20", High: "21", Low: "19", Close: "20"},
+			},
+		},
+		{
+			name: "buckets before the first price are left out", from: 3600, to: 10800,
+			prices: []models.TokenPrice{row(10800, "20", "21", "19", "20")},
+			want:   []response.PriceCandle{{Time: 10800, Open: "20", High: "21", Low: "19", Close: "20"}},
+		},
+		{
+			name: "the price before the range fills the first buckets", from: 3600, to: 10800,
+			prices:    []models.TokenPrice{row(10800, "20", "21", "19", "20")},
+			lastClose: "18", hasLast: true,
+			want: []response.PriceCandle{
+				flat(3600, "18"),
+				flat(7200, "18"),
+				{Time: 10800, Open: "20", High: "21", Low: "19", Close: "20"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bucketCandles(tt.prices, tt.lastClose, tt.hasLast, tt.from, tt.to, 3600)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bucketCandles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,484 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	consts "pledge-backend/api/common"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
+	"pledge-backend/api/models/request"
+	"pledge-backend/api/models/response"
+	"pledge-backend/chainclient"
+	"pledge-backend/config"
+	"pledge-backend/contract/bindings"
+	"pledge-backend/log"
+	"pledge-backend/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"gorm.io/gorm"
+)
+
+type ProposalService struct{}
+
+func NewProposal() *ProposalService {
+	return &ProposalService{}
+}
+
+// CreateProposal draft an admin call of the pledge pool, the call is abi encoded here so signers see exactly what is executed
+func (s *ProposalService) CreateProposal(req *request.CreateProposal, operator string, result *models.Proposal) int {
+	if !utils.IsContain(req.Method, consts.PROPOSAL_METHODS) {
+		return statecode.ProposalMethodErr
+	}
+	chain, ok := config.GetChain(utils.IntToString(req.ChainId))
+	if !ok {
+		return statecode.ChainIdErr
+	}
+
+	data, err := packProposalCall(req.Method, req.Params)
+	if err != nil {
+		log.Logger.Sugar().Info("proposal params err ", req.Method, " ", err)
+		return statecode.ProposalParamsErr
+	}
+
+	multiSign := models.NewMultiSign()
+	err = multiSign.Get(req.ChainId)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	var accounts []string
+	_ = json.Unmarshal([]byte(multiSign.MultiSignAccount), &accounts)
+	threshold := config.Config.Proposal.Threshold
+	if threshold <= 0 || len(accounts) < threshold {
+		return statecode.ProposalThresholdErr
+	}
+
+	params, _ := json.Marshal(req.Params)
+	proposal := models.Proposal{
+		ChainId:   req.ChainId,
+		Target:    common.HexToAddress(chain.PledgePoolToken).Hex(),
+		Method:    req.Method,
+		Params:    string(params),
+		Data:      hexutil.Encode(data),
+		Value:     "0",
+		Threshold: threshold,
+		Deadline:  time.Now().Unix() + config.Config.Proposal.ExpireTime,
+		CreatedBy: operator,
+	}
+	err = models.NewProposal().Create(&proposal)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	*result = proposal
+	return statecode.CommonSuccess
+}
+
+// ProposalList proposals of a chain, newest first
+func (s *ProposalService) ProposalList(req *request.ProposalList, result *[]models.Proposal) int {
+	proposals, err := models.NewProposal().List(req.ChainId, req.Status)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	for i := range proposals {
+		setExpired(&proposals[i])
+	}
+	*result = proposals
+	return statecode.CommonSuccess
+}
+
+// ProposalDetail a proposal with its signatures and the typed data the multi-sign accounts sign
+func (s *ProposalService) ProposalDetail(req *request.ProposalId, result *response.ProposalDetail) int {
+	proposal, errCode := s.getProposal(req.Id)
+	if errCode != statecode.CommonSuccess {
+		return errCode
+	}
+	signatures, err := models.NewProposal().Signatures(proposal.Id)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	typedData := proposalTypedData(&proposal)
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	setExpired(&proposal)
+	result.Proposal = proposal
+	result.Signatures = signatures
+	result.TypedData = typedData
+	result.Digest = hexutil.Encode(digest)
+	return statecode.CommonSuccess
+}
+
+// SignProposal add the EIP-712 signature of a multi-sign account, the proposal is approved at threshold signatures
+func (s *ProposalService) SignProposal(req *request.SignProposal, operator string, result *response.ProposalSign) int {
+	proposal, errCode := s.getProposal(req.Id)
+	if errCode != statecode.CommonSuccess {
+		return errCode
+	}
+	if proposal.Status != consts.PROPOSAL_STATUS_PENDING {
+		return statecode.ProposalStatusErr
+	}
+	if time.Now().Unix() > proposal.Deadline {
+		return statecode.ProposalExpired
+	}
+
+	digest, _, err := apitypes.TypedDataAndHash(proposalTypedData(&proposal))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	signer, err := recoverSigner(digest, req.Signature)
+	if err != nil {
+		return statecode.ProposalSignatureErr
+	}
+
+	// the signer set is read at signing time, so a rollback of the multi-sign also changes who can still sign
+	allowed, err := models.NewMultiSign().IsMultiSignAccount(proposal.ChainId, signer.String())
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	if !allowed {
+		return statecode.ProposalSignerNotAllowed
+	}
+
+	signatures, err := models.NewProposal().Signatures(proposal.Id)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	for _, signature := range signatures {
+		if signature.Signer == signer.String() {
+			return statecode.ProposalAlreadySigned
+		}
+	}
+
+	count, err := models.NewProposal().AddSignature(&proposal, &models.ProposalSignature{
+		ProposalId: proposal.Id,
+		Signer:     signer.String(),
+		Signature:  req.Signature,
+		CreatedBy:  operator,
+	})
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	result.Signer = signer.String()
+	result.Signatures = count
+	result.Threshold = proposal.Threshold
+	result.Status = consts.PROPOSAL_STATUS_PENDING
+	if count >= int64(proposal.Threshold) {
+		result.Status = consts.PROPOSAL_STATUS_APPROVED
+	}
+	return statecode.CommonSuccess
+}
+
+// ProposalPayload the call and its signatures once the proposal is approved, the signatures are off-chain approvals only
+func (s *ProposalService) ProposalPayload(req *request.ProposalId, result *response.ProposalPayload) int {
+	proposal, errCode := s.getProposal(req.Id)
+	if errCode != statecode.CommonSuccess {
+		return errCode
+	}
+	if proposal.Status != consts.PROPOSAL_STATUS_APPROVED {
+		return statecode.ProposalStatusErr
+	}
+	if time.Now().Unix() > proposal.Deadline {
+		return statecode.ProposalExpired
+	}
+
+	signatures, err := models.NewProposal().Signatures(proposal.Id)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	digest, _, err := apitypes.TypedDataAndHash(proposalTypedData(&proposal))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	result.Id = proposal.Id
+	result.ChainId = proposal.ChainId
+	result.Target = proposal.Target
+	result.Data = proposal.Data
+	result.Value = proposal.Value
+	result.Deadline = proposal.Deadline
+	result.Digest = hexutil.Encode(digest)
+	result.Signers = make([]string, 0, len(signatures))
+	result.Signatures = make([]string, 0, len(signatures))
+	packed := "0x"
+	for _, signature := range signatures {
+		result.Signers = append(result.Signers, signature.Signer)
+		result.Signatures = append(result.Signatures, signature.Signature)
+		packed += strings.TrimPrefix(signature.Signature, "0x")
+	}
+	result.PackedSignatures = packed
+	return statecode.CommonSuccess
+}
+
+// ExecuteProposal record the transaction that executed an approved proposal,
+// it has to be mined, successful and make the proposal call, directly or through the exec call of the chain's multi-sign wallet
+func (s *ProposalService) ExecuteProposal(req *request.ExecuteProposal, operator string) int {
+	proposal, errCode := s.getProposal(req.Id)
+	if errCode != statecode.CommonSuccess {
+		return errCode
+	}
+	if proposal.Status != consts.PROPOSAL_STATUS_APPROVED {
+		return statecode.ProposalStatusErr
+	}
+
+	hashBytes, err := hexutil.Decode(req.TxHash)
+	if err != nil || len(hashBytes) != common.HashLength {
+		return statecode.ProposalTxErr
+	}
+	txHash := common.BytesToHash(hashBytes)
+
+	chain, ok := config.GetChain(utils.IntToString(proposal.ChainId))
+	if !ok {
+		return statecode.ChainIdErr
+	}
+	ethereumConn, err := chainclient.GetClient(chain.ChainId)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	tx, isPending, err := ethereumConn.TransactionByHash(context.Background(), txHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return statecode.ProposalTxErr
+		}
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	if isPending || !executesProposal(tx, &proposal, chain.MultiSignWallet) {
+		return statecode.ProposalTxErr
+	}
+	executed, err := models.NewProposal().GetByTxHash(txHash.Hex())
+	if err == nil {
+		log.Logger.Sugar().Info("proposal tx already recorded ", txHash.Hex(), " for ", executed.Id)
+		return statecode.ProposalTxErr
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	receipt, err := ethereumConn.TransactionReceipt(context.Background(), txHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return statecode.ProposalTxErr
+		}
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return statecode.ProposalTxErr
+	}
+
+	err = models.NewProposal().SetExecuted(proposal.Id, txHash.Hex(), operator)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	log.Logger.Sugar().Info("proposal executed ", proposal.Id, " ", proposal.Method, " ", txHash.Hex(), " by ", operator)
+	return statecode.CommonSuccess
+}
+
+// executesProposal whether the transaction calls the proposal target with its data and value,
+// sent to the target itself or to the multi-sign wallet whose execTransaction makes that call
+func executesProposal(tx *types.Transaction, proposal *models.Proposal, multiSignWallet string) bool {
+	if tx.To() == nil {
+		return false
+	}
+	to, value, data := *tx.To(), tx.Value(), tx.Data()
+	if multiSignWallet != "" && to == common.HexToAddress(multiSignWallet) {
+		var ok bool
+		to, value, data, ok = unpackWalletExec(data)
+		if !ok {
+			return false
+		}
+	}
+
+	proposalData, err := hexutil.Decode(proposal.Data)
+	if err != nil {
+		return false
+	}
+	proposalValue, ok := new(big.Int).SetString(proposal.Value, 10)
+	if !ok {
+		return false
+	}
+	return to == common.HexToAddress(proposal.Target) && value.Cmp(proposalValue) == 0 && bytes.Equal(data, proposalData)
+}
+
+// unpackWalletExec the call made by an execTransaction of the multi-sign wallet, a delegatecall is not a proposal call
+func unpackWalletExec(input []byte) (common.Address, *big.Int, []byte, bool) {
+	walletAbi, err := bindings.MultiSignWalletMetaData.GetAbi()
+	if err != nil || len(input) < 4 {
+		return common.Address{}, nil, nil, false
+	}
+	method, err := walletAbi.MethodById(input[:4])
+	if err != nil || method.Name != "execTransaction" {
+		return common.Address{}, nil, nil, false
+	}
+	args, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return common.Address{}, nil, nil, false
+	}
+	to, ok1 := args[0].(common.Address)
+	value, ok2 := args[1].(*big.Int)
+	data, ok3 := args[2].([]byte)
+	operation, ok4 := args[3].(uint8)
+	if !ok1 || !ok2 || !ok3 || !ok4 || operation != 0 {
+		return common.Address{}, nil, nil, false
+	}
+	return to, value, data, true
+}
+
+func (s *ProposalService) getProposal(id int) (models.Proposal, int) {
+	proposal, err := models.NewProposal().GetById(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return proposal, statecode.ProposalNotFound
+		}
+		log.Logger.Error(err.Error())
+		return proposal, statecode.CommonErrServerErr
+	}
+	return proposal, statecode.CommonSuccess
+}
+
+// setExpired show a proposal that can not be executed anymore as expired
+func setExpired(proposal *models.Proposal) {
+	if proposal.Status != consts.PROPOSAL_STATUS_EXECUTED && time.Now().Unix() > proposal.Deadline {
+		proposal.Status = consts.PROPOSAL_STATUS_EXPIRED
+	}
+}
+
+// proposalTypedData EIP-712 typed data of a proposal, the domain binds it to the chain and the pledge pool contract
+func proposalTypedData(proposal *models.Proposal) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Proposal": {
+				{Name: "id", Type: "uint256"},
+				{Name: "target", Type: "address"},
+				{Name: "data", Type: "bytes"},
+				{Name: "value", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Proposal",
+		Domain: apitypes.TypedDataDomain{
+			Name:              config.Config.Proposal.DomainName,
+			Version:           config.Config.Proposal.DomainVersion,
+			ChainId:           math.NewHexOrDecimal256(int64(proposal.ChainId)),
+			VerifyingContract: proposal.Target,
+		},
+		Message: apitypes.TypedDataMessage{
+			"id":       strconv.Itoa(proposal.Id),
+			"target":   proposal.Target,
+			"data":     proposal.Data,
+			"value":    proposal.Value,
+			"deadline": strconv.FormatInt(proposal.Deadline, 10),
+		},
+	}
+}
+
+// packProposalCall abi encode a call of the pledge pool from string arguments
+func packProposalCall(method string, params []string) ([]byte, error) {
+	pledgePoolAbi, err := bindings.PledgePoolTokenMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	abiMethod, ok := pledgePoolAbi.Methods[method]
+	if !ok {
+		return nil, errors.New("method not in abi " + method)
+	}
+	if len(params) != len(abiMethod.Inputs) {
+		return nil, errors.New("want " + strconv.Itoa(len(abiMethod.Inputs)) + " params")
+	}
+
+	args := make([]interface{}, 0, len(params))
+	for i, input := range abiMethod.Inputs {
+		arg, err := convertAbiArg(input.Type, params[i])
+		if err != nil {
+			return nil, errors.New(input.Name + " " + err.Error())
+		}
+		args = append(args, arg)
+	}
+	return pledgePoolAbi.Pack(method, args...)
+}
+
+// convertAbiArg go value of a string for the abi types used by the admin methods
+func convertAbiArg(t abi.Type, param string) (interface{}, error) {
+	switch t.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(param) {
+			return nil, errors.New("invalid address")
+		}
+		return common.HexToAddress(param), nil
+	case abi.BoolTy:
+		return strconv.ParseBool(param)
+	case abi.UintTy, abi.IntTy:
+		n, ok := new(big.Int).SetString(param, 10)
+		if !ok {
+			return nil, errors.New("invalid number")
+		}
+		// the abi packer wraps values out of range, e.g. -1 as a uint256 is packed as 2^256-1
+		if t.T == abi.UintTy {
+			if n.Sign() < 0 || n.BitLen() > t.Size {
+				return nil, errors.New("number out of range")
+			}
+			switch t.Size {
+			case 8:
+				return uint8(n.Uint64()), nil
+			case 16:
+				return uint16(n.Uint64()), nil
+			case 32:
+				return uint32(n.Uint64()), nil
+			case 64:
+				return n.Uint64(), nil
+			default:
+				return n, nil
+			}
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, errors.New("number out of range")
+		}
+		switch t.Size {
+		case 8:
+			return int8(n.Int64()), nil
+		case 16:
+			return int16(n.Int64()), nil
+		case 32:
+			return int32(n.Int64()), nil
+		case 64:
+			return n.Int64(), nil
+		default:
+			return n, nil
+		}
+	}
+	return nil, errors.New("unsupported type " + t.String())
+}
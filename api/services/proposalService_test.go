@@ -0,0 +1,173 @@
+package services
+
+import (
+	"math/big"
+	"pledge-backend/api/models"
+	"pledge-backend/contract/bindings"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestConvertAbiArg(t *testing.T) {
+	abiType := func(name string) abi.Type {
+		typ, err := abi.NewType(name, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return typ
+	}
+	tests := []struct {
+		typ     string
+		param   string
+		want    interface{}
+		wantErr bool
+	}{
+		{typ: "address", param: "0x216f718A983FCCb462b338FA9c60f2A89199490c", want: common.HexToAddress("0x216f718A983FCCb462b338FA9c60f2A89199490c")},
+		{typ: "address", param: "0x1234", wantErr: true},
+		{typ: "bool", param: "true", want: true},
+		{typ: "bool", param: "yes", wantErr: true},
+		{typ: "uint256", param: "100000000", want: big.NewInt(100000000)},
+		{typ: "uint256", param: "1e8", wantErr: true},
+		{typ: "uint256", param: "-1", wantErr: true},
+		{typ: "uint256", param: "115792089237316195423570985008687907853269984665640564039457584007913129639935", want: new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))},
+		{typ: "uint256", param: "115792089237316195423570985008687907853269984665640564039457584007913129639936", wantErr: true},
+		{typ: "uint128", param: "340282366920938463463374607431768211456", wantErr: true},
+		{typ: "uint24", param: "16777215", want: big.NewInt(16777215)},
+		{typ: "uint24", param: "16777216", wantErr: true},
+		{typ: "uint64", param: "18446744073709551615", want: uint64(18446744073709551615)},
+		{typ: "uint64", param: "18446744073709551616", wantErr: true},
+		{typ: "uint64", param: "-1", wantErr: true},
+		{typ: "uint8", param: "255", want: uint8(255)},
+		{typ: "uint8", param: "256", wantErr: true},
+		{typ: "uint32", param: "7", want: uint32(7)},
+		{typ: "int64", param: "-5", want: int64(-5)},
+		{typ: "int8", param: "127", want: int8(127)},
+		{typ: "int8", param: "128", wantErr: true},
+		{typ: "int8", param: "-128", want: int8(-128)},
+		{typ: "int8", param: "-129", wantErr: true},
+		{typ: "int256", param: "-57896044618658097711785492504343953926634992332820282019728792003956564819968", want: new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 255))},
+		{typ: "int256", param: "-57896044618658097711785492504343953926634992332820282019728792003956564819969", wantErr: true},
+		{typ: "int256", param: "57896044618658097711785492504343953926634992332820282019728792003956564819968", wantErr: true},
+		{typ: "string", param: "pledge", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ+" "+tt.param, func(t *testing.T) {
+			got, err := convertAbiArg(abiType(tt.typ), tt.param)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("convertAbiArg() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("convertAbiArg() err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("convertAbiArg() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackProposalCall(t *testing.T) {
+	pledgePoolAbi, err := bindings.PledgePoolTokenMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	feeAddress := common.HexToAddress("0x0ff66Eb23C511ABd86fC676CE025Ca12caB2d5d4")
+	tests := []struct {
+		name    string
+		method  string
+		params  []string
+		args    []interface{}
+		wantErr bool
+	}{
+		{name: "set fee", method: "setFee", params: []string{"2000000", "3000000"}, args: []interface{}{big.NewInt(2000000), big.NewInt(3000000)}},
+		{name: "set fee address", method: "setFeeAddress", params: []string{feeAddress.Hex()}, args: []interface{}{feeAddress}},
+		{name: "set pause", method: "setPause", params: []string{}},
+		{name: "unknown method", method: "setOwner", params: []string{feeAddress.Hex()}, wantErr: true},
+		{name: "missing param", method: "setFee", params: []string{"2000000"}, wantErr: true},
+		{name: "invalid param", method: "setMinAmount", params: []string{"ten"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := packProposalCall(tt.method, tt.params)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("packProposalCall() = %x, want an error", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("packProposalCall() err = %v", err)
+			}
+			want, err := pledgePoolAbi.Pack(tt.method, tt.args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hexutil.Encode(data) != hexutil.Encode(want) {
+				t.Errorf("packProposalCall() = %x, want %x", data, want)
+			}
+		})
+	}
+}
+
+func TestExecutesProposal(t *testing.T) {
+	target := common.HexToAddress("0x216f718A983FCCb462b338FA9c60f2A89199490c")
+	wallet := common.HexToAddress("0x8a2E9A4b1B1b7E3C3f1d6F5d2fA6C1d3B4e5F607")
+	other := common.HexToAddress("0x0ff66Eb23C511ABd86fC676CE025Ca12caB2d5d4")
+	data, err := packProposalCall("setFee", []string{"2000000", "3000000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proposal := &models.Proposal{Target: target.Hex(), Data: hexutil.Encode(data), Value: "0"}
+
+	walletAbi, err := bindings.MultiSignWalletMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := func(to common.Address, callData []byte, operation uint8) []byte {
+		input, err := walletAbi.Pack("execTransaction", to, big.NewInt(0), callData, operation,
+			big.NewInt(0), big.NewInt(0), big.NewInt(0), common.Address{}, common.Address{}, []byte{0x01})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return input
+	}
+	transaction := func(to *common.Address, value int64, input []byte) *types.Transaction {
+		return types.NewTx(&types.LegacyTx{To: to, Value: big.NewInt(value), Data: input})
+	}
+	// the proposal call inside a larger call must not match
+	wrapped := append(append([]byte{0x12, 0x34, 0x56, 0x78}, data...), 0x00)
+
+	tests := []struct {
+		name   string
+		tx     *types.Transaction
+		wallet string
+		want   bool
+	}{
+		{name: "direct call", tx: transaction(&target, 0, data), want: true},
+		{name: "direct call with a wallet configured", tx: transaction(&target, 0, data), wallet: wallet.Hex(), want: true},
+		{name: "other contract", tx: transaction(&other, 0, data)},
+		{name: "contract creation", tx: transaction(nil, 0, data)},
+		{name: "other value", tx: transaction(&target, 1, data)},
+		{name: "call data inside other data", tx: transaction(&target, 0, wrapped)},
+		{name: "wallet exec", tx: transaction(&wallet, 0, exec(target, data, 0)), wallet: wallet.Hex(), want: true},
+		{name: "wallet not configured", tx: transaction(&wallet, 0, exec(target, data, 0))},
+		{name: "wallet exec of other contract", tx: transaction(&wallet, 0, exec(other, data, 0)), wallet: wallet.Hex()},
+		{name: "wallet delegatecall", tx: transaction(&wallet, 0, exec(target, data, 1)), wallet: wallet.Hex()},
+		{name: "wallet other method", tx: transaction(&wallet, 0, data), wallet: wallet.Hex()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := executesProposal(tt.tx, proposal, tt.wallet); got != tt.want {
+				t.Errorf("executesProposal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -28,5 +28,23 @@ func (c *SearchService) Search(req *request.Search) (int, int64, []models.Pool)
 		log.Logger.Error(err.Error())
 		return statecode.CommonErrServerErr, 0, nil
 	}
+
+	tokenMap, err := NewPoolMetrics().TokenMap(req.ChainID)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr, 0, nil
+	}
+	for i, p := range data {
+		data[i].Metrics = NewPoolMetrics().PoolMetrics(&PoolMetricsParam{
+			LendSupply:   p.LendSupply,
+			BorrowSupply: p.BorrowSupply,
+			InterestRate: p.InterestRate,
+			MartgageRate: p.MartgageRate,
+			SettleTime:   p.SettleTime,
+			EndTime:      p.EndTime,
+			LendToken:    p.LendTokenAddress,
+			BorrowToken:  p.BorrowTokenAddress,
+		}, tokenMap)
+	}
 	return 0, total, data
 }
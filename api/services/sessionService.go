@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
+	"pledge-backend/api/models/request"
+	"pledge-backend/api/models/response"
+	"pledge-backend/config"
+	"pledge-backend/log"
+	"pledge-backend/utils"
+	"time"
+)
+
+// startSession open a login session and issue its first access and refresh token
+func (s *UserService) startSession(username, role, clientIp, userAgent string, result *response.Login) int {
+	nowDateTime := utils.GetCurDateTimeFormat()
+	session := &models.AdminSession{
+		Id:          utils.UniqueId(),
+		Username:    username,
+		ClientIp:    clientIp,
+		UserAgent:   userAgent,
+		CreatedAt:   nowDateTime,
+		RefreshedAt: nowDateTime,
+		ExpireAt:    time.Now().Unix() + int64(config.Config.Jwt.RefreshExpireTime),
+	}
+	return s.issueTokens(session, role, result)
+}
+
+// issueTokens sign a new token pair for the session and save it, the session expiry is not extended by a refresh
+func (s *UserService) issueTokens(session *models.AdminSession, role string, result *response.Login) int {
+	accessToken, accessClaims, err := utils.CreateToken(session.Username, session.Id)
+	if err != nil {
+		log.Logger.Error("CreateToken" + err.Error())
+		return statecode.CommonErrServerErr
+	}
+	refreshToken, refreshClaims, err := utils.CreateRefreshToken(session.Username, session.Id)
+	if err != nil {
+		log.Logger.Error("CreateRefreshToken" + err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	session.AccessJti = accessClaims.Id
+	session.AccessExpireAt = accessClaims.ExpiresAt
+	err = models.NewAdminSession().SaveSession(session, refreshClaims.Id)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	result.TokenId = accessToken
+	result.RefreshToken = refreshToken
+	result.ExpireAt = accessClaims.ExpiresAt
+	result.Role = role
+	return statecode.CommonSuccess
+}
+
+// Refresh rotate a refresh token: the old one is used up and a new token pair is issued.
+// A refresh token that is used a second time means it leaked, so the whole session is revoked.
+func (s *UserService) Refresh(req *request.RefreshToken, result *response.Login) int {
+	claims, err := utils.ParseToken(req.RefreshToken, config.Config.Jwt.SecretKey, utils.TokenTypeRefresh)
+	if err != nil {
+		return statecode.RefreshTokenErr
+	}
+
+	session, err := models.NewAdminSession().GetSession(claims.Sid)
+	if err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			return statecode.RefreshTokenErr
+		}
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	used, err := models.NewAdminSession().UseRefreshToken(claims.Id)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	if !used {
+		log.Logger.Sugar().Info("refresh token reused, revoke session ", session.Username, " ", session.Id)
+		_ = models.NewAdminSession().RevokeSession(session)
+		return statecode.RefreshTokenErr
+	}
+
+	adminUser, err := models.NewAdminUser().GetByUsername(session.Username)
+	if err != nil {
+		_ = models.NewAdminSession().RevokeSession(session)
+		return statecode.RefreshTokenErr
+	}
+	// a wallet only keeps its session while it is a multi-sign account
+	if adminUser.IsWalletUser() {
+		allowed, err := s.isMultiSignWallet(adminUser.Username)
+		if err != nil {
+			log.Logger.Error(err.Error())
+			return statecode.CommonErrServerErr
+		}
+		if !allowed {
+			_ = models.NewAdminSession().RevokeSession(session)
+			return statecode.RefreshTokenErr
+		}
+	}
+
+	// the access token of the previous pair is replaced, it should not outlive the rotation
+	_ = models.NewAdminSession().RevokeToken(session.AccessJti, session.AccessExpireAt)
+	session.RefreshedAt = utils.GetCurDateTimeFormat()
+	return s.issueTokens(session, adminUser.Role, result)
+}
+
+// Logout revoke one session
+func (s *UserService) Logout(sessionId string) int {
+	session, err := models.NewAdminSession().GetSession(sessionId)
+	if err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			return statecode.CommonSuccess
+		}
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	err = models.NewAdminSession().RevokeSession(session)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	return statecode.CommonSuccess
+}
+
+// LogoutAll revoke every session of a username
+func (s *UserService) LogoutAll(username string) int {
+	err := models.NewAdminSession().RevokeAllSessions(username)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	return statecode.CommonSuccess
+}
+
+// Sessions active sessions of a username, currentSessionId is marked as current
+func (s *UserService) Sessions(username, currentSessionId string, result *[]models.AdminSession) int {
+	sessions, err := models.NewAdminSession().ListSessions(username)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].Id == currentSessionId
+	}
+	*result = sessions
+	return statecode.CommonSuccess
+}
+
+// RevokeSession log out one of the own sessions, e.g. a lost device
+func (s *UserService) RevokeSession(req *request.RevokeSession, username string) int {
+	session, err := models.NewAdminSession().GetSession(req.SessionId)
+	if err != nil {
+		if errors.Is(err, models.ErrSessionNotFound) {
+			return statecode.SessionNotFound
+		}
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	if session.Username != username {
+		return statecode.SessionNotFound
+	}
+	err = models.NewAdminSession().RevokeSession(session)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	return statecode.CommonSuccess
+}
+
+// AdminUserSessions active sessions of any admin user
+func (s *UserService) AdminUserSessions(req *request.AdminUserSessions, result *[]models.AdminSession) int {
+	adminUser, errCode := s.getAdminUser(req.Id)
+	if errCode != statecode.CommonSuccess {
+		return errCode
+	}
+	return s.Sessions(adminUser.Username, "", result)
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
+	"pledge-backend/api/models/request"
+	"pledge-backend/api/models/response"
+	"pledge-backend/config"
+	"pledge-backend/db"
+	"pledge-backend/log"
+	"pledge-backend/utils"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gorm.io/gorm"
+)
+
+const siweNoncePrefix = "siwe_nonce:"
+
+// siweClockSkew how far the wallet clock may be ahead when it sets Issued At
+const siweClockSkew = time.Minute
+
+// siweMessage fields of an EIP-4361 message
+type siweMessage struct {
+	Domain         string
+	Address        string
+	Uri            string
+	Version        string
+	ChainId        int
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+	NotBefore      time.Time
+}
+
+// Nonce single use nonce for a sign-in message, it expires after [siwe] nonce_expire seconds
+func (s *UserService) Nonce(result *response.Nonce) int {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	nonce := hex.EncodeToString(b)
+	err = db.RedisSetString(siweNoncePrefix+nonce, "1", config.Config.Siwe.NonceExpire)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	result.Nonce = nonce
+	return statecode.CommonSuccess
+}
+
+// SiweLogin log in with a signed EIP-4361 message, the signer has to be a multi-sign account of the chain in the message
+func (s *UserService) SiweLogin(req *request.SiweLogin, clientIp, userAgent string, result *response.Login) int {
+	message, err := parseSiweMessage(req.Message)
+	if err != nil {
+		log.Logger.Sugar().Info("siwe message err ", err)
+		return statecode.SiweMessageErr
+	}
+	errCode := checkSiweMessage(message, time.Now())
+	if errCode != statecode.CommonSuccess {
+		return errCode
+	}
+
+	signer, err := recoverSigner(accounts.TextHash([]byte(req.Message)), req.Signature)
+	if err != nil || !strings.EqualFold(signer.String(), message.Address) {
+		return statecode.SiweSignatureErr
+	}
+
+	// the nonce is consumed only by a valid signature, so a bad request can not burn someone else's nonce
+	deleted, err := db.RedisDelete(siweNoncePrefix + message.Nonce)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	if !deleted {
+		return statecode.SiweNonceErr
+	}
+
+	allowed, err := models.NewMultiSign().IsMultiSignAccount(message.ChainId, signer.String())
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	if !allowed {
+		return statecode.SiweNotAllowed
+	}
+
+	address := signer.String()
+	adminUser, err := models.NewAdminUser().GetByUsername(address)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Logger.Error(err.Error())
+			return statecode.CommonErrServerErr
+		}
+		adminUser, err = models.NewAdminUser().CreateWalletUser(address, config.Config.Siwe.DefaultRole)
+		if err != nil {
+			log.Logger.Error(err.Error())
+			return statecode.CommonErrServerErr
+		}
+	}
+
+	return s.startSession(address, adminUser.Role, clientIp, userAgent, result)
+}
+
+// isMultiSignWallet whether a wallet is still a multi-sign account of any enabled chain
+func (s *UserService) isMultiSignWallet(address string) (bool, error) {
+	for _, chain := range config.EnabledChains() {
+		allowed, err := models.NewMultiSign().IsMultiSignAccount(utils.StringToInt(chain.ChainId), address)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+	return false, nil
+}
+
+// RevokeRemovedWallets log out the wallet users that are no multi-sign account anymore, run after the multi-sign accounts changed
+func (s *UserService) RevokeRemovedWallets() error {
+	adminUsers, err := models.NewAdminUser().List()
+	if err != nil {
+		return err
+	}
+	for i := range adminUsers {
+		if !adminUsers[i].IsWalletUser() {
+			continue
+		}
+		allowed, err := s.isMultiSignWallet(adminUsers[i].Username)
+		if err != nil {
+			return err
+		}
+		if allowed {
+			continue
+		}
+		log.Logger.Sugar().Info("wallet is no multi-sign account anymore, revoke sessions ", adminUsers[i].Username)
+		err = models.NewAdminSession().RevokeAllSessions(adminUsers[i].Username)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSiweMessage whether the message is for this backend and valid at now. It has to be issued within the nonce
+// expiry, a nonce older than that is gone anyway, and its URI has to be on the configured origin.
+func checkSiweMessage(message *siweMessage, now time.Time) int {
+	if message.Domain != config.Config.Siwe.Domain || message.Version != "1" || !config.IsChainEnabled(message.ChainId) {
+		return statecode.SiweMessageErr
+	}
+	uri, err := url.Parse(message.Uri)
+	if err != nil || !strings.EqualFold(uri.Scheme+"://"+uri.Host, config.Config.Siwe.Origin) {
+		return statecode.SiweMessageErr
+	}
+
+	if message.IssuedAt.After(now.Add(siweClockSkew)) {
+		return statecode.SiweMessageErr
+	}
+	if now.Sub(message.IssuedAt) > time.Duration(config.Config.Siwe.NonceExpire)*time.Second {
+		return statecode.SiweExpired
+	}
+	if !message.ExpirationTime.IsZero() && now.After(message.ExpirationTime) {
+		return statecode.SiweExpired
+	}
+	if !message.NotBefore.IsZero() && now.Before(message.NotBefore) {
+		return statecode.SiweExpired
+	}
+	return statecode.CommonSuccess
+}
+
+// parseSiweMessage read the fields of an EIP-4361 message
+func parseSiweMessage(text string) (*siweMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if len(lines) < 2 {
+		return nil, errors.New("message too short")
+	}
+
+	const header = " wants you to sign in with your Ethereum account:"
+	if !strings.HasSuffix(lines[0], header) {
+		return nil, errors.New("missing header")
+	}
+	message := &siweMessage{Domain: strings.TrimSuffix(lines[0], header)}
+
+	message.Address = strings.TrimSpace(lines[1])
+	if !common.IsHexAddress(message.Address) {
+		return nil, errors.New("invalid address")
+	}
+
+	var err error
+	for _, line := range lines[2:] {
+		key, value, found := strings.Cut(line, ": ")
+		if !found {
+			continue
+		}
+		switch key {
+		case "URI":
+			message.Uri = value
+		case "Version":
+			message.Version = value
+		case "Chain ID":
+			message.ChainId = utils.StringToInt(value)
+		case "Nonce":
+			message.Nonce = value
+		case "Issued At":
+			message.IssuedAt, err = time.Parse(time.RFC3339, value)
+		case "Expiration Time":
+			message.ExpirationTime, err = time.Parse(time.RFC3339, value)
+		case "Not Before":
+			message.NotBefore, err = time.Parse(time.RFC3339, value)
+		}
+		if err != nil {
+			return nil, errors.New("invalid " + key)
+		}
+	}
+
+	if message.Uri == "" || message.Version == "" || message.ChainId == 0 || message.Nonce == "" || message.IssuedAt.IsZero() {
+		return nil, errors.New("missing field")
+	}
+	return message, nil
+}
+
+// recoverSigner address that signed the hash, e.g. the personal_sign or EIP-712 hash of a message
+func recoverSigner(hash []byte, signature string) (common.Address, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, errors.New("invalid signature length")
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const siweTestAddress = "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"
+
+func siweTestMessage(lines ...string) string {
+	return strings.Join(append([]string{
+		"v2-backend.pledger.finance wants you to sign in with your Ethereum account:",
+		siweTestAddress,
+		"",
+		"Sign in to Pledge Admin",
+		"",
+	}, lines...), "\n")
+}
+
+func TestParseSiweMessage(t *testing.T) {
+	fields := []string{
+		"URI: https://v2-backend.pledger.finance",
+		"Version: 1",
+		"Chain ID: 97",
+		"Nonce: 4f1a2b3c",
+		"Issued At: 2026-10-18T08:00:00Z",
+	}
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{name: "required fields", text: siweTestMessage(fields...)},
+		{name: "crlf line endings", text: strings.ReplaceAll(siweTestMessage(fields...), "\n", "\r\n")},
+		{name: "expiration and not before", text: siweTestMessage(append(fields, "Expiration Time: 2026-10-18T09:00:00Z", "Not Before: 2026-10-18T07:00:00Z")...)},
+		{name: "too short", text: "v2-backend.pledger.finance wants you to sign in with your Ethereum account:", wantErr: true},
+		{name: "missing header", text: strings.Replace(siweTestMessage(fields...), " wants you to sign in", " asks you to sign in", 1), wantErr: true},
+		{name: "invalid address", text: strings.Replace(siweTestMessage(fields...), siweTestAddress, "0x1234", 1), wantErr: true},
+		{name: "missing nonce", text: siweTestMessage(fields[0], fields[1], fields[2], fields[4]), wantErr: true},
+		{name: "missing chain id", text: siweTestMessage(fields[0], fields[1], fields[3], fields[4]), wantErr: true},
+		{name: "invalid issued at", text: siweTestMessage(fields[0], fields[1], fields[2], fields[3], "Issued At: yesterday"), wantErr: true},
+		{name: "invalid expiration time", text: siweTestMessage(append(fields, "Expiration Time: 2026-10-18")...), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := parseSiweMessage(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseSiweMessage() = %+v, want an error", message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSiweMessage() err = %v", err)
+			}
+			if message.Domain != "v2-backend.pledger.finance" || message.Address != siweTestAddress {
+				t.Errorf("domain, address = %s, %s", message.Domain, message.Address)
+			}
+			if message.Uri != "https://v2-backend.pledger.finance" || message.Version != "1" || message.ChainId != 97 || message.Nonce != "4f1a2b3c" {
+				t.Errorf("uri, version, chain id, nonce = %s, %s, %d, %s", message.Uri, message.Version, message.ChainId, message.Nonce)
+			}
+			if !message.IssuedAt.Equal(time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)) {
+				t.Errorf("issued at = %s", message.IssuedAt)
+			}
+		})
+	}
+}
+
+func TestRecoverSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	hash := accounts.TextHash([]byte(siweTestMessage("Nonce: 4f1a2b3c")))
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// wallets return the recovery id as 27 / 28
+	walletSig := append([]byte{}, sig...)
+	walletSig[crypto.RecoveryIDOffset] += 27
+
+	tests := []struct {
+		name      string
+		hash      []byte
+		signature string
+		want      string
+		wantErr   bool
+	}{
+		{name: "recovery id 0 / 1", hash: hash, signature: hexutil.Encode(sig), want: address.String()},
+		{name: "recovery id 27 / 28", hash: hash, signature: hexutil.Encode(walletSig), want: address.String()},
+		{name: "other hash", hash: crypto.Keccak256([]byte("other")), signature: hexutil.Encode(sig)},
+		{name: "not hex", hash: hash, signature: "signature", wantErr: true},
+		{name: "short signature", hash: hash, signature: hexutil.Encode(sig[:64]), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := recoverSigner(tt.hash, tt.signature)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("recoverSigner() = %s, want an error", signer)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("recoverSigner() err = %v", err)
+			}
+			if tt.want == "" {
+				if signer == address {
+					t.Errorf("recoverSigner() of another hash = %s, want another address", signer)
+				}
+				return
+			}
+			if signer.String() != tt.want {
+				t.Errorf("recoverSigner() = %s, want %s", signer, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSiweMessage(t *testing.T) {
+	siweConfig := config.Config.Siwe
+	defer func() {
+		config.Config.Siwe = siweConfig
+	}()
+	config.Config.Siwe.Domain = "v2-backend.pledger.finance"
+	config.Config.Siwe.Origin = "https://v2-backend.pledger.finance"
+	config.Config.Siwe.NonceExpire = 300
+
+	now := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+	message := func(change func(m *siweMessage)) *siweMessage {
+		m := &siweMessage{
+			Domain:   "v2-backend.pledger.finance",
+			Address:  siweTestAddress,
+			Uri:      "https://v2-backend.pledger.finance/login",
+			Version:  "1",
+			ChainId:  97,
+			Nonce:    "4f1a2b3c",
+			IssuedAt: now.Add(-time.Minute),
+		}
+		if change != nil {
+			change(m)
+		}
+		return m
+	}
+	tests := []struct {
+		name    string
+		message *siweMessage
+		want    int
+	}{
+		{name: "valid", message: message(nil), want: statecode.CommonSuccess},
+		{name: "uri of the origin", message: message(func(m *siweMessage) { m.Uri = "https://v2-backend.pledger.finance" }), want: statecode.CommonSuccess},
+		{name: "issued within the clock skew", message: message(func(m *siweMessage) { m.IssuedAt = now.Add(30 * time.Second) }), want: statecode.CommonSuccess},
+		{name: "other domain", message: message(func(m *siweMessage) { m.Domain = "evil.example" }), want: statecode.SiweMessageErr},
+		{name: "other version", message: message(func(m *siweMessage) { m.Version = "2" }), want: statecode.SiweMessageErr},
+		{name: "chain not enabled", message: message(func(m *siweMessage) { m.ChainId = 1 }), want: statecode.SiweMessageErr},
+		{name: "uri of another host", message: message(func(m *siweMessage) { m.Uri = "https://evil.example/login" }), want: statecode.SiweMessageErr},
+		{name: "uri of another scheme", message: message(func(m *siweMessage) { m.Uri = "http://v2-backend.pledger.finance" }), want: statecode.SiweMessageErr},
+		{name: "uri not a url", message: message(func(m *siweMessage) { m.Uri = "://" }), want: statecode.SiweMessageErr},
+		{name: "issued in the future", message: message(func(m *siweMessage) { m.IssuedAt = now.Add(2 * time.Minute) }), want: statecode.SiweMessageErr},
+		{name: "issued before the nonce expiry", message: message(func(m *siweMessage) { m.IssuedAt = now.Add(-6 * time.Minute) }), want: statecode.SiweExpired},
+		{name: "expired", message: message(func(m *siweMessage) { m.ExpirationTime = now.Add(-time.Second) }), want: statecode.SiweExpired},
+		{name: "not valid yet", message: message(func(m *siweMessage) { m.NotBefore = now.Add(time.Minute) }), want: statecode.SiweExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkSiweMessage(tt.message, now); got != tt.want {
+				t.Errorf("checkSiweMessage() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,16 @@
 package services
 
 import (
+	"errors"
+	consts "pledge-backend/api/common"
 	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models"
 	"pledge-backend/api/models/request"
 	"pledge-backend/api/models/response"
-	"pledge-backend/config"
-	"pledge-backend/db"
 	"pledge-backend/log"
 	"pledge-backend/utils"
+
+	"gorm.io/gorm"
 )
 
 type UserService struct{}
@@ -16,19 +19,123 @@ func NewUser() *UserService {
 	return &UserService{}
 }
 
-func (s *UserService) Login(req *request.Login, result *response.Login) int {
-	log.Logger.Sugar().Info("contractService", req)
-	if req.Name == "admin" && req.Password == "password" {
-		token, err := utils.CreateToken(req.Name)
-		if err != nil {
-			log.Logger.Error("CreateToken" + err.Error())
-			return statecode.CommonErrServerErr
+func (s *UserService) Login(req *request.Login, clientIp, userAgent string, result *response.Login) int {
+	log.Logger.Sugar().Info("contractService", req.Name)
+	adminUser, err := models.NewAdminUser().GetByUsername(req.Name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return statecode.NameOrPasswordErr
 		}
-		result.TokenId = token
-		//save to redis
-		_ = db.RedisSet(req.Name, "login_ok", config.Config.Jwt.ExpireTime)
-		return statecode.CommonSuccess
-	} else {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	if !utils.CheckPasswordHash(req.Password, adminUser.PasswordHash) {
 		return statecode.NameOrPasswordErr
 	}
+
+	return s.startSession(adminUser.Username, adminUser.Role, clientIp, userAgent, result)
+}
+
+// AdminUsers list the admin users
+func (s *UserService) AdminUsers(result *[]models.AdminUser) int {
+	adminUsers, err := models.NewAdminUser().List()
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	*result = adminUsers
+	return statecode.CommonSuccess
+}
+
+// CreateAdminUser add an admin user with a unique username
+func (s *UserService) CreateAdminUser(req *request.CreateAdminUser, result *models.AdminUser) int {
+	_, err := models.NewAdminUser().GetByUsername(req.Username)
+	if err == nil {
+		return statecode.AdminUserExist
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+
+	adminUser, err := models.NewAdminUser().Create(req.Username, req.Password, req.Role)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	*result = adminUser
+	return statecode.CommonSuccess
+}
+
+// UpdateAdminUser change the role or password, the user has to log in again after a password change
+func (s *UserService) UpdateAdminUser(req *request.UpdateAdminUser) int {
+	adminUser, errCode := s.getAdminUser(req.Id)
+	if errCode != statecode.CommonSuccess {
+		return errCode
+	}
+
+	if adminUser.Role == consts.ADMIN_ROLE_MULTISIG_ADMIN && req.Role != consts.ADMIN_ROLE_MULTISIG_ADMIN {
+		if errCode = s.checkNotLastAdmin(); errCode != statecode.CommonSuccess {
+			return errCode
+		}
+	}
+
+	err := models.NewAdminUser().Update(req.Id, req.Password, req.Role)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	if req.Password != "" {
+		_ = models.NewAdminSession().RevokeAllSessions(adminUser.Username)
+	}
+	return statecode.CommonSuccess
+}
+
+// DeleteAdminUser remove an admin user and its login, an admin can not remove itself
+func (s *UserService) DeleteAdminUser(req *request.DeleteAdminUser, operator string) int {
+	adminUser, errCode := s.getAdminUser(req.Id)
+	if errCode != statecode.CommonSuccess {
+		return errCode
+	}
+	if adminUser.Username == operator {
+		return statecode.PermissionDenied
+	}
+	if adminUser.Role == consts.ADMIN_ROLE_MULTISIG_ADMIN {
+		if errCode = s.checkNotLastAdmin(); errCode != statecode.CommonSuccess {
+			return errCode
+		}
+	}
+
+	err := models.NewAdminUser().Delete(req.Id)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	_ = models.NewAdminSession().RevokeAllSessions(adminUser.Username)
+	return statecode.CommonSuccess
+}
+
+func (s *UserService) getAdminUser(id int) (models.AdminUser, int) {
+	adminUser, err := models.NewAdminUser().GetById(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return adminUser, statecode.AdminUserNotFound
+		}
+		log.Logger.Error(err.Error())
+		return adminUser, statecode.CommonErrServerErr
+	}
+	return adminUser, statecode.CommonSuccess
+}
+
+// checkNotLastAdmin keep at least one multisig-admin so the admin users can still be managed
+func (s *UserService) checkNotLastAdmin() int {
+	count, err := models.NewAdminUser().CountByRole(consts.ADMIN_ROLE_MULTISIG_ADMIN)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return statecode.CommonErrServerErr
+	}
+	if count <= 1 {
+		return statecode.LastAdminErr
+	}
+	return statecode.CommonSuccess
 }
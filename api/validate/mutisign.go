@@ -6,6 +6,7 @@ import (
 	"io"
 	"pledge-backend/api/common/statecode"
 	"pledge-backend/api/models/request"
+	"pledge-backend/config"
 )
 
 type MutiSign struct{}
@@ -17,7 +18,7 @@ func NewMutiSign() *MutiSign {
 func (v *MutiSign) SetMultiSign(c *gin.Context, req *request.SetMultiSign) int {
 
 	err := c.ShouldBind(req)
-	if req.ChainId != 97 && req.ChainId != 56 {
+	if !config.IsChainEnabled(req.ChainId) {
 		return statecode.ChainIdErr
 	}
 	if err == io.EOF {
@@ -38,7 +39,7 @@ func (v *MutiSign) SetMultiSign(c *gin.Context, req *request.SetMultiSign) int {
 func (v *MutiSign) GetMultiSign(c *gin.Context, req *request.GetMultiSign) int {
 
 	err := c.ShouldBind(req)
-	if req.ChainId != 97 && req.ChainId != 56 {
+	if !config.IsChainEnabled(req.ChainId) {
 		return statecode.ChainIdErr
 	}
 	if err == io.EOF {
@@ -55,3 +56,66 @@ func (v *MutiSign) GetMultiSign(c *gin.Context, req *request.GetMultiSign) int {
 
 	return statecode.CommonSuccess
 }
+
+func (v *MutiSign) MultiSignHistory(c *gin.Context, req *request.MultiSignHistory) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		errs, _ := err.(validator.ValidationErrors)
+		for _, e := range errs {
+			if e.Field() == "ChainId" && e.Tag() == "required" {
+				return statecode.ChainIdEmpty
+			}
+		}
+		return statecode.ParameterEmptyErr
+	}
+	if !config.IsChainEnabled(req.ChainId) {
+		return statecode.ChainIdErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *MutiSign) MultiSignDiff(c *gin.Context, req *request.MultiSignDiff) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		errs, _ := err.(validator.ValidationErrors)
+		for _, e := range errs {
+			if e.Field() == "ChainId" && e.Tag() == "required" {
+				return statecode.ChainIdEmpty
+			}
+		}
+		return statecode.ParameterEmptyErr
+	}
+	if !config.IsChainEnabled(req.ChainId) {
+		return statecode.ChainIdErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *MutiSign) RollbackMultiSign(c *gin.Context, req *request.RollbackMultiSign) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		errs, _ := err.(validator.ValidationErrors)
+		for _, e := range errs {
+			if e.Field() == "ChainId" && e.Tag() == "required" {
+				return statecode.ChainIdEmpty
+			}
+		}
+		return statecode.ParameterEmptyErr
+	}
+	if !config.IsChainEnabled(req.ChainId) {
+		return statecode.ChainIdErr
+	}
+
+	return statecode.CommonSuccess
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"pledge-backend/api/common/statecode"
 	"pledge-backend/api/models/request"
+	"pledge-backend/config"
 )
 
 type PoolBaseInfo struct{}
@@ -28,7 +29,7 @@ func (v *PoolBaseInfo) PoolBaseInfo(c *gin.Context, req *request.PoolBaseInfo) i
 		return statecode.CommonErrServerErr
 	}
 
-	if req.ChainId != 97 && req.ChainId != 56 {
+	if !config.IsChainEnabled(req.ChainId) {
 		return statecode.ChainIdErr
 	}
 
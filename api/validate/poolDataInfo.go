@@ -6,6 +6,7 @@ import (
 	"io"
 	"pledge-backend/api/common/statecode"
 	"pledge-backend/api/models/request"
+	"pledge-backend/config"
 )
 
 type PoolDataInfo struct{}
@@ -28,7 +29,7 @@ func (v *PoolDataInfo) PoolDataInfo(c *gin.Context, req *request.PoolDataInfo) i
 		return statecode.CommonErrServerErr
 	}
 
-	if req.ChainId != 97 && req.ChainId != 56 {
+	if !config.IsChainEnabled(req.ChainId) {
 		return statecode.ChainIdErr
 	}
 
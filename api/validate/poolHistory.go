@@ -0,0 +1,64 @@
+package validate
+
+import (
+	"io"
+	consts "pledge-backend/api/common"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models/request"
+	"pledge-backend/config"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PoolHistory struct{}
+
+func NewPoolHistory() *PoolHistory {
+	return &PoolHistory{}
+}
+
+func (v *PoolHistory) PoolHistory(c *gin.Context, req *request.PoolHistory) int {
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		errs := err.(validator.ValidationErrors)
+		for _, e := range errs {
+			if e.Field() == "ChainId" && e.Tag() == "required" {
+				return statecode.ChainIdEmpty
+			}
+			if e.Field() == "PoolId" && e.Tag() == "required" {
+				return statecode.PoolIdEmpty
+			}
+		}
+		return statecode.CommonErrServerErr
+	}
+
+	if !config.IsChainEnabled(req.ChainId) {
+		return statecode.ChainIdErr
+	}
+
+	if req.Interval == "" {
+		req.Interval = "1h"
+	}
+	interval, ok := consts.POOL_HISTORY_INTERVALS[req.Interval]
+	if !ok {
+		return statecode.IntervalErr
+	}
+
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+	if req.From == 0 {
+		req.From = req.To - 7*24*3600
+	}
+	if req.From < 0 || req.From > req.To {
+		return statecode.TimeRangeErr
+	}
+	if (req.To-req.From)/interval >= consts.POOL_HISTORY_MAX_POINTS {
+		return statecode.TimeRangeErr
+	}
+
+	return statecode.CommonSuccess
+}
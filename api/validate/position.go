@@ -0,0 +1,45 @@
+package validate
+
+import (
+	"io"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models/request"
+	"pledge-backend/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type Position struct{}
+
+func NewPosition() *Position {
+	return &Position{}
+}
+
+func (v *Position) Positions(c *gin.Context, req *request.Positions) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		errs := err.(validator.ValidationErrors)
+		for _, e := range errs {
+			if e.Field() == "ChainId" && e.Tag() == "required" {
+				return statecode.ChainIdEmpty
+			}
+		}
+		return statecode.CommonErrServerErr
+	}
+
+	if !config.IsChainEnabled(req.ChainId) {
+		return statecode.ChainIdErr
+	}
+
+	req.Address = c.Param("address")
+	if !common.IsHexAddress(req.Address) {
+		return statecode.AddressErr
+	}
+
+	return statecode.CommonSuccess
+}
@@ -0,0 +1,68 @@
+package validate
+
+import (
+	"io"
+	consts "pledge-backend/api/common"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models/request"
+	"pledge-backend/config"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PriceHistory struct{}
+
+func NewPriceHistory() *PriceHistory {
+	return &PriceHistory{}
+}
+
+func (v *PriceHistory) PriceHistory(c *gin.Context, req *request.PriceHistory) int {
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		errs, _ := err.(validator.ValidationErrors)
+		for _, e := range errs {
+			if e.Field() == "ChainId" && e.Tag() == "required" {
+				return statecode.ChainIdEmpty
+			}
+			if e.Field() == "Token" && e.Tag() == "required" {
+				return statecode.AddressErr
+			}
+		}
+		return statecode.CommonErrServerErr
+	}
+
+	if !config.IsChainEnabled(req.ChainId) {
+		return statecode.ChainIdErr
+	}
+	if !common.IsHexAddress(req.Token) {
+		return statecode.AddressErr
+	}
+
+	if req.Interval == "" {
+		req.Interval = "1h"
+	}
+	interval, ok := consts.PRICE_HISTORY_INTERVALS[req.Interval]
+	if !ok {
+		return statecode.IntervalErr
+	}
+
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+	if req.From == 0 {
+		req.From = req.To - 24*3600
+	}
+	if req.From < 0 || req.From > req.To {
+		return statecode.TimeRangeErr
+	}
+	if (req.To-req.From)/interval >= consts.PRICE_HISTORY_MAX_POINTS {
+		return statecode.TimeRangeErr
+	}
+
+	return statecode.CommonSuccess
+}
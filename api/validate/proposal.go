@@ -0,0 +1,94 @@
+package validate
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"pledge-backend/api/common/statecode"
+	"pledge-backend/api/models/request"
+	"pledge-backend/config"
+)
+
+type Proposal struct{}
+
+func NewProposal() *Proposal {
+	return &Proposal{}
+}
+
+func (v *Proposal) CreateProposal(c *gin.Context, req *request.CreateProposal) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		errs, _ := err.(validator.ValidationErrors)
+		for _, e := range errs {
+			if e.Field() == "ChainId" && e.Tag() == "required" {
+				return statecode.ChainIdEmpty
+			}
+		}
+		return statecode.ParameterEmptyErr
+	}
+	if !config.IsChainEnabled(req.ChainId) {
+		return statecode.ChainIdErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *Proposal) ProposalList(c *gin.Context, req *request.ProposalList) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		errs, _ := err.(validator.ValidationErrors)
+		for _, e := range errs {
+			if e.Field() == "ChainId" && e.Tag() == "required" {
+				return statecode.ChainIdEmpty
+			}
+		}
+		return statecode.ParameterEmptyErr
+	}
+	if !config.IsChainEnabled(req.ChainId) {
+		return statecode.ChainIdErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *Proposal) ProposalId(c *gin.Context, req *request.ProposalId) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		return statecode.ParameterEmptyErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *Proposal) SignProposal(c *gin.Context, req *request.SignProposal) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		return statecode.ParameterEmptyErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *Proposal) ExecuteProposal(c *gin.Context, req *request.ExecuteProposal) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		return statecode.ParameterEmptyErr
+	}
+
+	return statecode.CommonSuccess
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"pledge-backend/api/common/statecode"
 	"pledge-backend/api/models/request"
+	"pledge-backend/config"
 )
 
 type Search struct{}
@@ -29,7 +30,7 @@ func (s *Search) Search(c *gin.Context, req *request.Search) int {
 		return statecode.CommonErrServerErr
 	}
 
-	if req.ChainID != 97 && req.ChainID != 56 {
+	if !config.IsChainEnabled(req.ChainID) {
 		return statecode.ChainIdErr
 	}
 
@@ -6,6 +6,7 @@ import (
 	"io"
 	"pledge-backend/api/common/statecode"
 	"pledge-backend/api/models/request"
+	"pledge-backend/config"
 )
 
 type TokenList struct{}
@@ -29,7 +30,7 @@ func (v *TokenList) TokenList(c *gin.Context, req *request.TokenList) int {
 		return statecode.CommonErrServerErr
 	}
 
-	if req.ChainId != 97 && req.ChainId != 56 {
+	if !config.IsChainEnabled(req.ChainId) {
 		return statecode.ChainIdErr
 	}
 
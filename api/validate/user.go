@@ -4,8 +4,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"io"
+	consts "pledge-backend/api/common"
 	"pledge-backend/api/common/statecode"
 	"pledge-backend/api/models/request"
+	"pledge-backend/utils"
 )
 
 type User struct{}
@@ -34,3 +36,101 @@ func (v *User) Login(c *gin.Context, req *request.Login) int {
 
 	return statecode.CommonSuccess
 }
+
+func (v *User) CreateAdminUser(c *gin.Context, req *request.CreateAdminUser) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		return statecode.ParameterEmptyErr
+	}
+
+	if !utils.IsPassword(req.Password) {
+		return statecode.PasswordErr
+	}
+	if _, ok := consts.ADMIN_ROLE_RANK[req.Role]; !ok {
+		return statecode.RoleErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *User) UpdateAdminUser(c *gin.Context, req *request.UpdateAdminUser) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		return statecode.ParameterEmptyErr
+	}
+
+	if req.Password != "" && !utils.IsPassword(req.Password) {
+		return statecode.PasswordErr
+	}
+	if _, ok := consts.ADMIN_ROLE_RANK[req.Role]; !ok {
+		return statecode.RoleErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *User) DeleteAdminUser(c *gin.Context, req *request.DeleteAdminUser) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		return statecode.ParameterEmptyErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *User) SiweLogin(c *gin.Context, req *request.SiweLogin) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		return statecode.ParameterEmptyErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *User) RefreshToken(c *gin.Context, req *request.RefreshToken) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		return statecode.ParameterEmptyErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *User) RevokeSession(c *gin.Context, req *request.RevokeSession) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		return statecode.ParameterEmptyErr
+	}
+
+	return statecode.CommonSuccess
+}
+
+func (v *User) AdminUserSessions(c *gin.Context, req *request.AdminUserSessions) int {
+
+	err := c.ShouldBind(req)
+	if err == io.EOF {
+		return statecode.ParameterEmptyErr
+	} else if err != nil {
+		return statecode.ParameterEmptyErr
+	}
+
+	return statecode.CommonSuccess
+}
@@ -0,0 +1,418 @@
+package chainclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"pledge-backend/config"
+	"pledge-backend/log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	StrategyPriority   = "priority"
+	StrategyRoundRobin = "round_robin"
+)
+
+var (
+	_ bind.ContractBackend         = (*Client)(nil)
+	_ bind.DeployBackend           = (*Client)(nil)
+	_ bind.BlockHashContractCaller = (*Client)(nil)
+)
+
+// endpoint one rpc url of a chain
+type endpoint struct {
+	url     string
+	client  *ethclient.Client
+	healthy bool
+}
+
+// Client rpc client of one chain that fails over between its urls.
+// It implements bind.ContractBackend so it can be passed to the contract bindings.
+type Client struct {
+	name      string
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int // round robin cursor
+}
+
+func newClient(name string, urls []string) *Client {
+	c := &Client{name: name}
+	for _, url := range urls {
+		e := &endpoint{url: url}
+		client, err := ethclient.Dial(url)
+		if err != nil {
+			log.Logger.Sugar().Error("chainclient dial err ", name, " ", url, " ", err)
+		} else {
+			e.client, e.healthy = client, true
+		}
+		c.endpoints = append(c.endpoints, e)
+	}
+	return c
+}
+
+// Call run fn with a per call timeout, on a network error it is retried on the next url with backoff
+func (c *Client) Call(ctx context.Context, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rpcConfig := config.Config.Rpc
+	backoff := time.Duration(rpcConfig.RetryBackoff) * time.Millisecond
+	timeout := callTimeout()
+
+	var err error
+	endpoints := c.order()
+	for attempt := 0; attempt <= rpcConfig.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		e := endpoints[attempt%len(endpoints)]
+		client := c.dial(e)
+		if client == nil {
+			err = errors.New("rpc url is not reachable " + e.url)
+			continue
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = fn(callCtx, client)
+		cancel()
+		if err == nil || ctx.Err() != nil || !isNetworkErr(err) {
+			return err
+		}
+		log.Logger.Sugar().Error("chainclient call err ", c.name, " ", e.url, " ", err)
+		c.setHealthy(e, false)
+	}
+	return err
+}
+
+// callTimeout timeout of one rpc call, 10 seconds if call_timeout is not set
+func callTimeout() time.Duration {
+	timeout := time.Duration(config.Config.Rpc.CallTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return timeout
+}
+
+// order healthy urls first, by config order or rotated for round robin, unhealthy ones are the last resort
+func (c *Client) order() []*endpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start := 0
+	if config.Config.Rpc.Strategy == StrategyRoundRobin {
+		start = c.next % len(c.endpoints)
+		c.next++
+	}
+	healthy := make([]*endpoint, 0, len(c.endpoints))
+	unhealthy := make([]*endpoint, 0)
+	for i := range c.endpoints {
+		e := c.endpoints[(start+i)%len(c.endpoints)]
+		if e.healthy {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// dial connect the url again if the first dial failed
+func (c *Client) dial(e *endpoint) *ethclient.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e.client == nil {
+		client, err := ethclient.Dial(e.url)
+		if err != nil {
+			return nil
+		}
+		e.client = client
+	}
+	return e.client
+}
+
+func (c *Client) setHealthy(e *endpoint, healthy bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.healthy != healthy {
+		log.Logger.Sugar().Info("chainclient ", c.name, " ", e.url, " healthy ", healthy)
+	}
+	e.healthy = healthy
+}
+
+// healthCheck mark every url healthy or not by asking it for the block number
+func (c *Client) healthCheck() {
+	interval := time.Duration(config.Config.Rpc.HealthCheckInterval) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, e := range c.endpoints {
+			client := c.dial(e)
+			if client == nil {
+				c.setHealthy(e, false)
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), callTimeout())
+			_, err := client.BlockNumber(ctx)
+			cancel()
+			c.setHealthy(e, err == nil)
+		}
+	}
+}
+
+// isNetworkErr false if the node answered, e.g. a revert or a missing receipt, those are not retried on another url
+func isNetworkErr(err error) bool {
+	if errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	return true
+}
+
+// ChainID chain id reported by the node
+func (c *Client) ChainID(ctx context.Context) (chainId *big.Int, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		chainId, err = client.ChainID(ctx)
+		return err
+	})
+	return
+}
+
+// BlockNumber most recent block number
+func (c *Client) BlockNumber(ctx context.Context) (number uint64, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		number, err = client.BlockNumber(ctx)
+		return err
+	})
+	return
+}
+
+// BlockByNumber block with transactions, nil number is the latest block
+func (c *Client) BlockByNumber(ctx context.Context, number *big.Int) (block *types.Block, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		block, err = client.BlockByNumber(ctx, number)
+		return err
+	})
+	return
+}
+
+// BlockByHash block with transactions
+func (c *Client) BlockByHash(ctx context.Context, hash common.Hash) (block *types.Block, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		block, err = client.BlockByHash(ctx, hash)
+		return err
+	})
+	return
+}
+
+// BalanceAt native balance of an account, nil number is the latest block
+func (c *Client) BalanceAt(ctx context.Context, account common.Address, number *big.Int) (balance *big.Int, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		balance, err = client.BalanceAt(ctx, account, number)
+		return err
+	})
+	return
+}
+
+// TransactionByHash transaction and whether it is still pending
+func (c *Client) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		tx, isPending, err = client.TransactionByHash(ctx, hash)
+		return err
+	})
+	return
+}
+
+// TransactionReceipt receipt of a mined transaction
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		receipt, err = client.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return
+}
+
+// BlockReceipts receipts of all the transactions of a block
+func (c *Client) BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (receipts []*types.Receipt, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		receipts, err = client.BlockReceipts(ctx, blockNrOrHash)
+		return err
+	})
+	return
+}
+
+// HeaderByNumber block header, nil number is the latest header
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		header, err = client.HeaderByNumber(ctx, number)
+		return err
+	})
+	return
+}
+
+// HeaderByHash block header
+func (c *Client) HeaderByHash(ctx context.Context, hash common.Hash) (header *types.Header, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		header, err = client.HeaderByHash(ctx, hash)
+		return err
+	})
+	return
+}
+
+// CodeAt contract code of an account
+func (c *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		code, err = client.CodeAt(ctx, account, blockNumber)
+		return err
+	})
+	return
+}
+
+// CodeAtHash contract code of an account at a block hash
+func (c *Client) CodeAtHash(ctx context.Context, account common.Address, blockHash common.Hash) (code []byte, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		code, err = client.CodeAtHash(ctx, account, blockHash)
+		return err
+	})
+	return
+}
+
+// CallContractAtHash execute a message call on the state of a block hash (EIP-1898)
+func (c *Client) CallContractAtHash(ctx context.Context, call ethereum.CallMsg, blockHash common.Hash) (result []byte, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		result, err = client.CallContractAtHash(ctx, call, blockHash)
+		return err
+	})
+	return
+}
+
+// CallContract execute a message call
+func (c *Client) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) (result []byte, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		result, err = client.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return
+}
+
+// PendingCodeAt contract code of an account in the pending state
+func (c *Client) PendingCodeAt(ctx context.Context, account common.Address) (code []byte, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		code, err = client.PendingCodeAt(ctx, account)
+		return err
+	})
+	return
+}
+
+// PendingNonceAt nonce of an account in the pending state
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		nonce, err = client.PendingNonceAt(ctx, account)
+		return err
+	})
+	return
+}
+
+// SuggestGasPrice legacy gas price
+func (c *Client) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		price, err = client.SuggestGasPrice(ctx)
+		return err
+	})
+	return
+}
+
+// SuggestGasTipCap gas tip cap of dynamic fee transactions
+func (c *Client) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		tip, err = client.SuggestGasTipCap(ctx)
+		return err
+	})
+	return
+}
+
+// EstimateGas gas needed by a message call
+func (c *Client) EstimateGas(ctx context.Context, call ethereum.CallMsg) (gas uint64, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		gas, err = client.EstimateGas(ctx, call)
+		return err
+	})
+	return
+}
+
+// SendTransaction send a signed transaction, a retry that reaches a node which already has or mined it is a success
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	attempt := 0
+	return c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		attempt++
+		err := client.SendTransaction(ctx, tx)
+		if err == nil || attempt == 1 {
+			return err
+		}
+		// an earlier attempt may have been broadcast before its error, the node then knows or already mined the transaction
+		if strings.Contains(err.Error(), "already known") {
+			return nil
+		}
+		if strings.Contains(err.Error(), "nonce too low") {
+			_, _, txErr := client.TransactionByHash(ctx, tx.Hash())
+			if txErr == nil {
+				return nil
+			}
+		}
+		return err
+	})
+}
+
+// FilterLogs logs matching a filter query
+func (c *Client) FilterLogs(ctx context.Context, query ethereum.FilterQuery) (logs []types.Log, err error) {
+	err = c.Call(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		logs, err = client.FilterLogs(ctx, query)
+		return err
+	})
+	return
+}
+
+// SubscribeFilterLogs log subscription on the first reachable url, a subscription is not moved to another url
+func (c *Client) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (sub ethereum.Subscription, err error) {
+	for _, e := range c.order() {
+		client := c.dial(e)
+		if client == nil {
+			continue
+		}
+		sub, err = client.SubscribeFilterLogs(ctx, query, ch)
+		if err == nil {
+			return sub, nil
+		}
+	}
+	if err == nil {
+		err = errors.New("no rpc url is reachable for " + c.name)
+	}
+	return nil, err
+}
@@ -0,0 +1,43 @@
+package chainclient
+
+import (
+	"errors"
+	"pledge-backend/config"
+	"sync"
+)
+
+var (
+	clientsMu sync.Mutex
+	clients   = make(map[string]*Client)
+)
+
+// GetClient long-lived failover client of a chain in the [[chains]] registry
+func GetClient(chainId string) (*Client, error) {
+	chain, ok := config.GetChain(chainId)
+	if !ok {
+		return nil, errors.New("chain is not enabled " + chainId)
+	}
+	return GetClientByUrls(chainId, chain.NetUrls)
+}
+
+// GetStudyClient client of the eth url in [study]
+func GetStudyClient() (*Client, error) {
+	return GetClientByUrls("study", []string{config.Config.Study.EthUrl})
+}
+
+// GetClientByUrls long-lived failover client for rpc urls that are not in the chain registry, created on first use
+func GetClientByUrls(name string, urls []string) (*Client, error) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	if c, ok := clients[name]; ok {
+		return c, nil
+	}
+	if len(urls) == 0 {
+		return nil, errors.New("no rpc url for " + name)
+	}
+	c := newClient(name, urls)
+	clients[name] = c
+	go c.healthCheck()
+	return c, nil
+}
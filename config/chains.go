@@ -0,0 +1,30 @@
+package config
+
+import "strconv"
+
+// EnabledChains chains the schedule jobs and the api work on
+func EnabledChains() []ChainConfig {
+	chains := make([]ChainConfig, 0, len(Config.Chains))
+	for _, c := range Config.Chains {
+		if c.Enabled {
+			chains = append(chains, c)
+		}
+	}
+	return chains
+}
+
+// GetChain enabled chain by chain id, false if it is unknown or disabled
+func GetChain(chainId string) (ChainConfig, bool) {
+	for _, c := range Config.Chains {
+		if c.Enabled && c.ChainId == chainId {
+			return c, true
+		}
+	}
+	return ChainConfig{}, false
+}
+
+// IsChainEnabled chain id check of the request validators
+func IsChainEnabled(chainId int) bool {
+	_, ok := GetChain(strconv.Itoa(chainId))
+	return ok
+}
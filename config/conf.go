@@ -5,14 +5,25 @@ var Config *Conf
 type Conf struct {
 	Mysql        MysqlConfig
 	Redis        RedisConfig
-	TestNet      TestNetConfig
-	MainNet      MainNetConfig
+	Chains       []ChainConfig
+	Study        StudyConfig
 	Token        TokenConfig
 	Email        EmailConfig
 	DefaultAdmin DefaultAdminConfig
 	Threshold    ThresholdConfig
 	Jwt          JwtConfig
 	Env          EnvConfig
+	Indexer      IndexerConfig
+	Risk         RiskConfig
+	Keeper       KeeperConfig
+	Rpc          RpcConfig
+	Siwe         SiweConfig
+	Proposal     ProposalConfig
+	Kucoin       KucoinConfig
+	Price        PriceConfig
+	OraclePusher OraclePusherConfig
+	PriceHistory PriceHistoryConfig
+	Backfill     BackfillConfig
 }
 
 type EnvConfig struct {
@@ -25,6 +36,85 @@ type EnvConfig struct {
 	TaskExtendDuration int64  `toml:"task_extend_duration"`
 }
 
+type IndexerConfig struct {
+	BatchSize     uint64 `toml:"batch_size"`    // max blocks per filter query
+	Confirmations uint64 `toml:"confirmations"` // blocks behind head that are considered stable
+}
+
+type RiskConfig struct {
+	WarningBands []string `toml:"warning_bands"` // health factors that raise an alert when crossed, e.g. ["1.2", "1.1", "1.05"]
+}
+
+type KeeperConfig struct {
+	Enabled        bool  `toml:"enabled"`
+	SendTimeout    int64 `toml:"send_timeout"`    // seconds to sign and send a transaction
+	ReceiptTimeout int64 `toml:"receipt_timeout"` // seconds to wait for the receipt
+	MaxAttempts    int   `toml:"max_attempts"`    // sends of a transition before the keeper gives up on it and sends an email
+	RetryInterval  int64 `toml:"retry_interval"`  // seconds after a failed attempt before the next one, doubled per attempt
+}
+
+type RpcConfig struct {
+	Strategy            string `toml:"strategy"`              // priority: first healthy url in config order, round_robin: rotate over healthy urls
+	CallTimeout         int64  `toml:"call_timeout"`          // seconds per rpc call
+	MaxRetries          int    `toml:"max_retries"`           // retries on another url after a network error
+	RetryBackoff        int64  `toml:"retry_backoff"`         // milliseconds before the first retry, doubled on each retry
+	HealthCheckInterval int64  `toml:"health_check_interval"` // seconds between block number checks of every url
+}
+
+type SiweConfig struct {
+	Domain      string `toml:"domain"`       // domain the sign-in message has to be issued for
+	Origin      string `toml:"origin"`       // scheme and host the URI of the sign-in message has to be on
+	NonceExpire int    `toml:"nonce_expire"` // seconds a nonce can be used
+	DefaultRole string `toml:"default_role"` // role of an admin user created by the first wallet login
+}
+
+type ProposalConfig struct {
+	Threshold     int    `toml:"threshold"`      // signatures of multi-sign accounts needed to approve a proposal
+	ExpireTime    int64  `toml:"expire_time"`    // seconds a proposal can collect signatures and be executed
+	DomainName    string `toml:"domain_name"`    // EIP-712 domain name
+	DomainVersion string `toml:"domain_version"` // EIP-712 domain version
+}
+
+type KucoinConfig struct {
+	StaleThreshold      int64 `toml:"stale_threshold"`       // seconds without a tick after which the plgr price is invalid
+	ReconnectBackoffMin int64 `toml:"reconnect_backoff_min"` // seconds before the first reconnect, doubled after every failure
+	ReconnectBackoffMax int64 `toml:"reconnect_backoff_max"` // seconds
+}
+
+type PriceConfig struct {
+	Sources      []string                `toml:"sources"`       // oracle, kucoin and names of http_sources, aggregated by median
+	MaxDeviation float64                 `toml:"max_deviation"` // percent from the median above which a source price is rejected, with 3 or more prices
+	MinSources   int                     `toml:"min_sources"`   // accepted sources needed to update a token price
+	HttpSources  []HttpPriceSourceConfig `toml:"http_sources"`
+}
+
+// HttpPriceSourceConfig json api returning a usd price, a [[price.http_sources]] entry of the toml
+type HttpPriceSourceConfig struct {
+	Name  string `toml:"name"`
+	Url   string `toml:"url"`   // {chain_id}, {token} and {symbol} are replaced
+	Field string `toml:"field"` // dot separated path of the price in the response, e.g. "data.price"
+}
+
+type OraclePusherConfig struct {
+	Deviation      float64 `toml:"deviation"`       // percent between the off-chain and the oracle price that triggers a push
+	Heartbeat      int64   `toml:"heartbeat"`       // seconds after the last push at which a price is pushed even if it did not move
+	SendTimeout    int64   `toml:"send_timeout"`    // seconds to sign and send a transaction
+	ReceiptTimeout int64   `toml:"receipt_timeout"` // seconds to wait for the receipt
+}
+
+type PriceHistoryConfig struct {
+	RawRetention    int64 `toml:"raw_retention"`    // seconds every observed price change is kept before it is merged into hourly candles
+	HourlyRetention int64 `toml:"hourly_retention"` // seconds hourly candles are kept before they are merged into daily candles
+}
+
+type BackfillConfig struct {
+	Enabled    bool   `toml:"enabled"`
+	StartBlock uint64 `toml:"start_block"` // first block of the range
+	EndBlock   uint64 `toml:"end_block"`   // last block of the range, 0 follows the finalized head
+	BatchSize  uint64 `toml:"batch_size"`  // blocks saved in one db transaction
+	Workers    int    `toml:"workers"`     // blocks fetched at the same time
+}
+
 type ThresholdConfig struct {
 	PledgePoolTokenThresholdBnb string `toml:"pledge_pool_token_threshold_bnb"`
 }
@@ -46,8 +136,9 @@ type DefaultAdminConfig struct {
 }
 
 type JwtConfig struct {
-	SecretKey  string `toml:"secret_key"`
-	ExpireTime int    `toml:"expire_time"` // duration, s
+	SecretKey         string `toml:"secret_key"`
+	ExpireTime        int    `toml:"expire_time"`         // access token duration, s
+	RefreshExpireTime int    `toml:"refresh_expire_time"` // refresh token and session duration, s
 }
 
 type TokenConfig struct {
@@ -65,23 +156,27 @@ type MysqlConfig struct {
 	MaxLifeTime  int    `toml:"max_life_time"`
 }
 
-type TestNetConfig struct {
-	ChainId              string `toml:"chain_id"`
-	NetUrl               string `toml:"net_url"`
-	PlgrAddress          string `toml:"plgr_address"`
-	PledgePoolToken      string `toml:"pledge_pool_token"`
-	BscPledgeOracleToken string `toml:"bsc_pledge_oracle_token"`
-	TestEthUrl           string `toml:"test_eth_url"`
-	StoreAddress         string `toml:"store_address"`
-	PrivateKey           string `toml:"private_key"`
+// ChainConfig one EVM chain the pledge contracts are deployed on, a [[chains]] entry of the toml
+type ChainConfig struct {
+	ChainId          string   `toml:"chain_id"`
+	Name             string   `toml:"name"`
+	NetUrls          []string `toml:"net_urls"` // rpc urls in priority order, failover is configured in [rpc]
+	PledgePoolToken  string   `toml:"pledge_pool_token"`
+	DeployBlock      uint64   `toml:"deploy_block"` // block the pledge pool was deployed at, the event indexer starts here
+	OracleToken      string   `toml:"oracle_token"`
+	PlgrAddress      string   `toml:"plgr_address"`
+	NativeSymbol     string   `toml:"native_symbol"`
+	PlgrPricePush    bool     `toml:"plgr_price_push"`    // push the ku-coin plgr price to the oracle of this chain
+	OraclePushTokens []string `toml:"oracle_push_tokens"` // other tokens whose off-chain price is pushed to the oracle
+	MulticallAddress string   `toml:"multicall_address"`  // Multicall3 used for batch reads, the canonical address if empty
+	MultiSignWallet  string   `toml:"multi_sign_wallet"`  // Safe wallet whose execTransaction runs approved proposals, empty if they are sent directly
+	Enabled          bool     `toml:"enabled"`
 }
 
-type MainNetConfig struct {
-	ChainId              string `toml:"chain_id"`
-	NetUrl               string `toml:"net_url"`
-	PlgrAddress          string `toml:"plgr_address"`
-	PledgePoolToken      string `toml:"pledge_pool_token"`
-	BscPledgeOracleToken string `toml:"bsc_pledge_oracle_token"`
+type StudyConfig struct {
+	EthUrl       string `toml:"eth_url"`
+	StoreAddress string `toml:"store_address"`
+	PrivateKey   string `toml:"private_key"`
 }
 
 type RedisConfig struct {
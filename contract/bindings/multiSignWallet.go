@@ -0,0 +1,202 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// MultiSignWalletMetaData contains all meta data concerning the MultiSignWallet contract.
+var MultiSignWalletMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"to\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"value\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"data\",\"type\":\"bytes\"},{\"internalType\":\"enumEnum.Operation\",\"name\":\"operation\",\"type\":\"uint8\"},{\"internalType\":\"uint256\",\"name\":\"safeTxGas\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"baseGas\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"gasPrice\",\"type\":\"uint256\"},{\"internalType\":\"address\",\"name\":\"gasToken\",\"type\":\"address\"},{\"internalType\":\"addresspayable\",\"name\":\"refundReceiver\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"signatures\",\"type\":\"bytes\"}],\"name\":\"execTransaction\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"success\",\"type\":\"bool\"}],\"stateMutability\":\"payable\",\"type\":\"function\"}]",
+}
+
+// MultiSignWalletABI is the input ABI used to generate the binding from.
+// Deprecated: Use MultiSignWalletMetaData.ABI instead.
+var MultiSignWalletABI = MultiSignWalletMetaData.ABI
+
+// MultiSignWallet is an auto generated Go binding around an Ethereum contract.
+type MultiSignWallet struct {
+	MultiSignWalletCaller     // Read-only binding to the contract
+	MultiSignWalletTransactor // Write-only binding to the contract
+	MultiSignWalletFilterer   // Log filterer for contract events
+}
+
+// MultiSignWalletCaller is an auto generated read-only Go binding around an Ethereum contract.
+type MultiSignWalletCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MultiSignWalletTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type MultiSignWalletTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MultiSignWalletFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type MultiSignWalletFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// MultiSignWalletSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type MultiSignWalletSession struct {
+	Contract     *MultiSignWallet  // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// MultiSignWalletCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type MultiSignWalletCallerSession struct {
+	Contract *MultiSignWalletCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts          // Call options to use throughout this session
+}
+
+// MultiSignWalletTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type MultiSignWalletTransactorSession struct {
+	Contract     *MultiSignWalletTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts          // Transaction auth options to use throughout this session
+}
+
+// MultiSignWalletRaw is an auto generated low-level Go binding around an Ethereum contract.
+type MultiSignWalletRaw struct {
+	Contract *MultiSignWallet // Generic contract binding to access the raw methods on
+}
+
+// MultiSignWalletCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type MultiSignWalletCallerRaw struct {
+	Contract *MultiSignWalletCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// MultiSignWalletTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type MultiSignWalletTransactorRaw struct {
+	Contract *MultiSignWalletTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewMultiSignWallet creates a new instance of MultiSignWallet, bound to a specific deployed contract.
+func NewMultiSignWallet(address common.Address, backend bind.ContractBackend) (*MultiSignWallet, error) {
+	contract, err := bindMultiSignWallet(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiSignWallet{MultiSignWalletCaller: MultiSignWalletCaller{contract: contract}, MultiSignWalletTransactor: MultiSignWalletTransactor{contract: contract}, MultiSignWalletFilterer: MultiSignWalletFilterer{contract: contract}}, nil
+}
+
+// NewMultiSignWalletCaller creates a new read-only instance of MultiSignWallet, bound to a specific deployed contract.
+func NewMultiSignWalletCaller(address common.Address, caller bind.ContractCaller) (*MultiSignWalletCaller, error) {
+	contract, err := bindMultiSignWallet(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiSignWalletCaller{contract: contract}, nil
+}
+
+// NewMultiSignWalletTransactor creates a new write-only instance of MultiSignWallet, bound to a specific deployed contract.
+func NewMultiSignWalletTransactor(address common.Address, transactor bind.ContractTransactor) (*MultiSignWalletTransactor, error) {
+	contract, err := bindMultiSignWallet(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiSignWalletTransactor{contract: contract}, nil
+}
+
+// NewMultiSignWalletFilterer creates a new log filterer instance of MultiSignWallet, bound to a specific deployed contract.
+func NewMultiSignWalletFilterer(address common.Address, filterer bind.ContractFilterer) (*MultiSignWalletFilterer, error) {
+	contract, err := bindMultiSignWallet(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiSignWalletFilterer{contract: contract}, nil
+}
+
+// bindMultiSignWallet binds a generic wrapper to an already deployed contract.
+func bindMultiSignWallet(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := MultiSignWalletMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_MultiSignWallet *MultiSignWalletRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _MultiSignWallet.Contract.MultiSignWalletCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_MultiSignWallet *MultiSignWalletRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _MultiSignWallet.Contract.MultiSignWalletTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_MultiSignWallet *MultiSignWalletRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _MultiSignWallet.Contract.MultiSignWalletTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_MultiSignWallet *MultiSignWalletCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _MultiSignWallet.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_MultiSignWallet *MultiSignWalletTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _MultiSignWallet.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_MultiSignWallet *MultiSignWalletTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _MultiSignWallet.Contract.contract.Transact(opts, method, params...)
+}
+
+// ExecTransaction is a paid mutator transaction binding the contract method 0x6a761202.
+//
+// Solidity: function execTransaction(address to, uint256 value, bytes data, uint8 operation, uint256 safeTxGas, uint256 baseGas, uint256 gasPrice, address gasToken, address refundReceiver, bytes signatures) payable returns(bool success)
+func (_MultiSignWallet *MultiSignWalletTransactor) ExecTransaction(opts *bind.TransactOpts, to common.Address, value *big.Int, data []byte, operation uint8, safeTxGas *big.Int, baseGas *big.Int, gasPrice *big.Int, gasToken common.Address, refundReceiver common.Address, signatures []byte) (*types.Transaction, error) {
+	return _MultiSignWallet.contract.Transact(opts, "execTransaction", to, value, data, operation, safeTxGas, baseGas, gasPrice, gasToken, refundReceiver, signatures)
+}
+
+// ExecTransaction is a paid mutator transaction binding the contract method 0x6a761202.
+//
+// Solidity: function execTransaction(address to, uint256 value, bytes data, uint8 operation, uint256 safeTxGas, uint256 baseGas, uint256 gasPrice, address gasToken, address refundReceiver, bytes signatures) payable returns(bool success)
+func (_MultiSignWallet *MultiSignWalletSession) ExecTransaction(to common.Address, value *big.Int, data []byte, operation uint8, safeTxGas *big.Int, baseGas *big.Int, gasPrice *big.Int, gasToken common.Address, refundReceiver common.Address, signatures []byte) (*types.Transaction, error) {
+	return _MultiSignWallet.Contract.ExecTransaction(&_MultiSignWallet.TransactOpts, to, value, data, operation, safeTxGas, baseGas, gasPrice, gasToken, refundReceiver, signatures)
+}
+
+// ExecTransaction is a paid mutator transaction binding the contract method 0x6a761202.
+//
+// Solidity: function execTransaction(address to, uint256 value, bytes data, uint8 operation, uint256 safeTxGas, uint256 baseGas, uint256 gasPrice, address gasToken, address refundReceiver, bytes signatures) payable returns(bool success)
+func (_MultiSignWallet *MultiSignWalletTransactorSession) ExecTransaction(to common.Address, value *big.Int, data []byte, operation uint8, safeTxGas *big.Int, baseGas *big.Int, gasPrice *big.Int, gasToken common.Address, refundReceiver common.Address, signatures []byte) (*types.Transaction, error) {
+	return _MultiSignWallet.Contract.ExecTransaction(&_MultiSignWallet.TransactOpts, to, value, data, operation, safeTxGas, baseGas, gasPrice, gasToken, refundReceiver, signatures)
+}
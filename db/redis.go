@@ -113,7 +113,7 @@ func RedisGetString(key string) (string, error) {
 }
 
 // RedisSetInt64  set int64 value by key
-func RedisSetInt64(key string, data int64, time int) error {
+func RedisSetInt64(key string, data int64, aliveSeconds int) error {
 	conn := RedisConn.Get()
 	defer func() {
 		_ = conn.Close()
@@ -122,16 +122,14 @@ func RedisSetInt64(key string, data int64, time int) error {
 	if err != nil {
 		return err
 	}
-	_, err = redis.Int64(conn.Do("set", key, value))
+	if aliveSeconds > 0 {
+		_, err = redis.String(conn.Do("set", key, value, "EX", aliveSeconds))
+	} else {
+		_, err = redis.String(conn.Do("set", key, value))
+	}
 	if err != nil {
 		return err
 	}
-	if time != 0 {
-		_, err = redis.Int64(conn.Do("expire", key, time))
-		if err != nil {
-			return err
-		}
-	}
 	return nil
 }
 
@@ -218,6 +216,38 @@ func RedisFlushDB() error {
 	return nil
 }
 
+// RedisDeleteByPattern 删除匹配 pattern 的所有 Key, 用 scan 遍历不阻塞 redis
+func RedisDeleteByPattern(pattern string) error {
+	conn := RedisConn.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+	cursor := "0"
+	for {
+		values, err := redis.Values(conn.Do("scan", cursor, "match", pattern, "count", 1000))
+		if err != nil {
+			return err
+		}
+		cursor, err = redis.String(values[0], nil)
+		if err != nil {
+			return err
+		}
+		keys, err := redis.Strings(values[1], nil)
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			_, err = conn.Do("del", redis.Args{}.AddFlat(keys)...)
+			if err != nil {
+				return err
+			}
+		}
+		if cursor == "0" {
+			return nil
+		}
+	}
+}
+
 // RedisGetHashOne 获取Heah其中一个值
 func RedisGetHashOne(key, name string) (interface{}, error) {
 	conn := RedisConn.Get()
@@ -328,6 +358,16 @@ func RedisSAdd(k, v string) int64 {
 	return reply.(int64)
 }
 
+// RedisSRem 删除集合元素
+func RedisSRem(k, v string) error {
+	conn := RedisConn.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err := conn.Do("srem", k, v)
+	return err
+}
+
 // RedisSmembers 获取集合元素
 func RedisSmembers(k string) ([]string, error) {
 	conn := RedisConn.Get()
@@ -396,3 +436,74 @@ func RedisDelList(setName string) error {
 	_, err := conn.Do("del", setName)
 	return err
 }
+
+// RedisPublish 发布消息到频道
+func RedisPublish(channel string, data []byte) error {
+	conn := RedisConn.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err := conn.Do("publish", channel, data)
+	return err
+}
+
+// RedisPSubscribe 按模式订阅频道, 阻塞直到连接出错
+func RedisPSubscribe(pattern string, handler func(channel string, data []byte)) error {
+	conn := redis.PubSubConn{Conn: RedisConn.Get()}
+	defer func() {
+		_ = conn.Close()
+	}()
+	err := conn.PSubscribe(pattern)
+	if err != nil {
+		return err
+	}
+	for {
+		switch v := conn.Receive().(type) {
+		case redis.Message:
+			handler(v.Channel, v.Data)
+		case error:
+			return v
+		}
+	}
+}
+
+// renewLockScript 仅当锁仍属于 value 时续期
+var renewLockScript = redis.NewScript(1, `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("expire", KEYS[1], ARGV[2]) else return 0 end`)
+
+// releaseLockScript 仅当锁仍属于 value 时释放
+var releaseLockScript = redis.NewScript(1, `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`)
+
+// RedisTryLock 获取锁, value 标识持有者
+func RedisTryLock(key, value string, aliveSeconds int) (bool, error) {
+	conn := RedisConn.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err := redis.String(conn.Do("set", key, value, "NX", "EX", aliveSeconds))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RedisRenewLock 续期自己持有的锁, false 表示锁已丢失
+func RedisRenewLock(key, value string, aliveSeconds int) (bool, error) {
+	conn := RedisConn.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+	return redis.Bool(renewLockScript.Do(conn, key, value, aliveSeconds))
+}
+
+// RedisReleaseLock 释放自己持有的锁
+func RedisReleaseLock(key, value string) error {
+	conn := RedisConn.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, err := releaseLockScript.Do(conn, key, value)
+	return err
+}
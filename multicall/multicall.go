@@ -0,0 +1,123 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"pledge-backend/chainclient"
+	"pledge-backend/config"
+	"pledge-backend/contract/bindings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Multicall3Address Multicall3 is deployed at the same address on bsc, bsc testnet and most evm chains
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// BatchSize calls sent in one aggregate3 eth_call
+const BatchSize = 200
+
+// ErrCallFailed the call reverted inside the batch
+var ErrCallFailed = errors.New("multicall call failed")
+
+// Call one view call of a batch
+type Call struct {
+	Target common.Address
+	Abi    *abi.ABI
+	Method string
+	Args   []interface{}
+}
+
+// Result of a call, in the order of the calls
+type Result struct {
+	Success    bool
+	ReturnData []byte
+	call       Call
+}
+
+// Unpack decode the return data into out like abi.UnpackIntoInterface: a pointer to the single output
+// or to a struct whose fields are the named outputs
+func (r Result) Unpack(out interface{}) error {
+	return r.UnpackWith(r.call.Abi, out)
+}
+
+// UnpackWith decode the return data with another abi of the same method, e.g. a bytes32 symbol
+func (r Result) UnpackWith(parsed *abi.ABI, out interface{}) error {
+	if !r.Success {
+		return ErrCallFailed
+	}
+	return parsed.UnpackIntoInterface(out, r.call.Method, r.ReturnData)
+}
+
+// Reader batches the view calls of a chain through Multicall3, every call of a reader is made at the same block.
+// The calls are made by block hash (EIP-1898), so the state read is the one of BlockHash even if it is reorged meanwhile.
+type Reader struct {
+	multicall   *bindings.Multicall3Caller
+	BlockNumber *big.Int
+	BlockHash   common.Hash
+}
+
+// NewReader Reader pinned to the current head of the chain
+func NewReader(chain *config.ChainConfig) (*Reader, error) {
+	conn, err := chainclient.GetClient(chain.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	address := chain.MulticallAddress
+	if address == "" {
+		address = Multicall3Address
+	}
+	multicall, err := bindings.NewMulticall3Caller(common.HexToAddress(address), conn)
+	if err != nil {
+		return nil, err
+	}
+	header, err := conn.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{
+		multicall:   multicall,
+		BlockNumber: header.Number,
+		BlockHash:   header.Hash(),
+	}, nil
+}
+
+// Aggregate Run the calls in aggregate3 batches of BatchSize, a reverted call only fails its own result.
+// aggregate3 is payable in Multicall3, the abi declares it view so it is sent as an eth_call.
+func (r *Reader) Aggregate(calls []Call) ([]Result, error) {
+	results := make([]Result, 0, len(calls))
+	for start := 0; start < len(calls); start += BatchSize {
+		end := start + BatchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		calls3 := make([]bindings.Multicall3Call3, 0, end-start)
+		for _, call := range calls[start:end] {
+			callData, err := call.Abi.Pack(call.Method, call.Args...)
+			if err != nil {
+				return nil, err
+			}
+			calls3 = append(calls3, bindings.Multicall3Call3{
+				Target:       call.Target,
+				AllowFailure: true,
+				CallData:     callData,
+			})
+		}
+
+		returnData, err := r.multicall.Aggregate3(&bind.CallOpts{BlockHash: r.BlockHash}, calls3)
+		if err != nil {
+			return nil, err
+		}
+		for i, data := range returnData {
+			results = append(results, Result{
+				Success:    data.Success,
+				ReturnData: data.ReturnData,
+				call:       calls[start+i],
+			})
+		}
+	}
+	return results, nil
+}
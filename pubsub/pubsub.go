@@ -0,0 +1,90 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"pledge-backend/db"
+	"strings"
+)
+
+// ChannelPrefix redis channel of a topic is ChannelPrefix + topic
+const ChannelPrefix = "ws:"
+
+// topic kinds, a topic is "<kind>:<key>"
+const (
+	TopicPrice    = "price"    // price:<chainId>:<token> oracle price, price:<symbol> exchange price
+	TopicPool     = "pool"     // pool:<chainId>:<poolId> pool base info and state
+	TopicPoolData = "poolData" // poolData:<chainId>:<poolId> settle / finish / liquidation amounts
+	TopicPosition = "position" // position:<chainId>:<address> events of a wallet
+)
+
+// MarketPriceSymbol exchange pair of the PLGR price
+const MarketPriceSymbol = "PLGR-USDT"
+
+func PriceTopic(chainId, token string) string {
+	return TopicPrice + ":" + chainId + ":" + strings.ToLower(token)
+}
+
+func MarketPriceTopic(symbol string) string {
+	return TopicPrice + ":" + symbol
+}
+
+func PoolTopic(chainId, poolId string) string {
+	return TopicPool + ":" + chainId + ":" + poolId
+}
+
+func PoolDataTopic(chainId, poolId string) string {
+	return TopicPoolData + ":" + chainId + ":" + poolId
+}
+
+func PositionTopic(chainId, address string) string {
+	return TopicPosition + ":" + chainId + ":" + strings.ToLower(address)
+}
+
+// NormalizeTopic check the form of a topic sent by a client, addresses are lower cased so they match the published topic
+func NormalizeTopic(topic string) (string, bool) {
+	parts := strings.Split(topic, ":")
+	isNumber := func(s string) bool {
+		if s == "" {
+			return false
+		}
+		for _, c := range s {
+			if c < '0' || c > '9' {
+				return false
+			}
+		}
+		return true
+	}
+	isAddress := func(s string) bool {
+		return len(s) == 42 && strings.HasPrefix(s, "0x")
+	}
+
+	switch {
+	case len(parts) == 2 && parts[0] == TopicPrice && parts[1] != "":
+		return MarketPriceTopic(strings.ToUpper(parts[1])), true
+	case len(parts) == 3 && parts[0] == TopicPrice && isNumber(parts[1]) && isAddress(parts[2]):
+		return PriceTopic(parts[1], parts[2]), true
+	case len(parts) == 3 && parts[0] == TopicPool && isNumber(parts[1]) && isNumber(parts[2]):
+		return topic, true
+	case len(parts) == 3 && parts[0] == TopicPoolData && isNumber(parts[1]) && isNumber(parts[2]):
+		return topic, true
+	case len(parts) == 3 && parts[0] == TopicPosition && isNumber(parts[1]) && isAddress(parts[2]):
+		return PositionTopic(parts[1], parts[2]), true
+	}
+	return "", false
+}
+
+// Publish send data as json to the subscribers of a topic on every api instance
+func Publish(topic string, data interface{}) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return db.RedisPublish(ChannelPrefix+topic, dataBytes)
+}
+
+// Subscribe call handler with the topic and json data of every published message, it blocks until the redis connection fails
+func Subscribe(handler func(topic string, data []byte)) error {
+	return db.RedisPSubscribe(ChannelPrefix+"*", func(channel string, data []byte) {
+		handler(strings.TrimPrefix(channel, ChannelPrefix), data)
+	})
+}
@@ -0,0 +1,36 @@
+package pubsub
+
+import "testing"
+
+func TestNormalizeTopic(t *testing.T) {
+	tests := []struct {
+		topic  string
+		want   string
+		wantOk bool
+	}{
+		{topic: "price:plgr-usdt", want: "price:PLGR-USDT", wantOk: true},
+		{topic: "price:97:0xDc6dF65b2fA0322394a8af628Ad25Be7D7F413c2", want: "price:97:0xdc6df65b2fa0322394a8af628ad25be7d7f413c2", wantOk: true},
+		{topic: "pool:97:1", want: "pool:97:1", wantOk: true},
+		{topic: "poolData:56:12", want: "poolData:56:12", wantOk: true},
+		{topic: "position:97:0x9858EfFD232B4033E47d90003D41EC34EcaEda94", want: "position:97:0x9858effd232b4033e47d90003d41ec34ecaeda94", wantOk: true},
+		{topic: "price:"},
+		{topic: "price:bsc:0xDc6dF65b2fA0322394a8af628Ad25Be7D7F413c2"},
+		{topic: "price:97:0x1234"},
+		{topic: "pool:97"},
+		{topic: "pool:97:one"},
+		{topic: "pool::1"},
+		{topic: "poolData:97:-1"},
+		{topic: "position:97:9858EfFD232B4033E47d90003D41EC34EcaEda9400"},
+		{topic: "position:97:0x9858EfFD232B4033E47d90003D41EC34EcaEda94:1"},
+		{topic: "block:97:1"},
+		{topic: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			got, ok := NormalizeTopic(tt.topic)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("NormalizeTopic(%q) = %q, %v, want %q, %v", tt.topic, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
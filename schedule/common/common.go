@@ -6,6 +6,7 @@ import (
 )
 
 var PlgrAdminPrivateKey string
+var KeeperPrivateKey string
 
 func GetEnv() {
 
@@ -17,4 +18,9 @@ func GetEnv() {
 		// panic("environment variable is not set")
 	}
 
+	KeeperPrivateKey, ok = os.LookupEnv("keeper_private_key")
+	if !ok {
+		log.Logger.Error("keeper environment variable is not set")
+	}
+
 }
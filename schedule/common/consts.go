@@ -0,0 +1,12 @@
+package common
+
+import "github.com/shopspring/decimal"
+
+// pool states of the pledge pool contract
+const (
+	PoolStateMatch     = "0" // before settle
+	PoolStateExecution = "1" // after settle, the only state that can be liquidated
+)
+
+// RateDecimal oracle prices, martgageRate and autoLiquidateThreshold are scaled by 1e8 in the contract
+var RateDecimal = decimal.NewFromInt(100000000)
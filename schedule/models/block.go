@@ -1,21 +1,97 @@
 package models
 
-import "github.com/ethereum/go-ethereum/core/types"
+import (
+	"pledge-backend/db"
 
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
+)
+
+// Block head cached in redis and block cached in the block table of the api
 type Block struct {
+	Id           uint64 `json:"-" gorm:"column:id;primaryKey"`
 	Hash         string
+	ParentHash   string
 	Nonce        uint64
 	Number       uint64
 	Time         uint64
 	Transactions uint64
+	Canonical    bool `json:"-" gorm:"column:canonical;default:true"`
 }
 
 func NewBlock(block *types.Block) *Block {
 	return &Block{
 		Hash:         block.Hash().String(),
+		ParentHash:   block.ParentHash().String(),
 		Nonce:        block.Nonce(),
 		Number:       block.Number().Uint64(),
 		Time:         block.Time(),
 		Transactions: uint64(block.Transactions().Len()),
+		Canonical:    true,
+	}
+}
+
+func (b *Block) TableName() string {
+	return "block"
+}
+
+// CanonicalBlocksFrom Cached canonical blocks from number from on, by number
+func (b *Block) CanonicalBlocksFrom(from uint64) (map[uint64]Block, error) {
+	blocks := make([]Block, 0)
+	err := db.Mysql.Table("block").Where("number>=? and canonical=?", from, true).Find(&blocks).Debug().Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[uint64]Block, len(blocks))
+	for _, block := range blocks {
+		res[block.Number] = block
+	}
+	return res, nil
+}
+
+// Save Save the block as the canonical block of its number, the other cached blocks of the number are orphaned.
+// The api and the scheduler both write the block table through it.
+func (b *Block) Save() error {
+	return db.Mysql.Transaction(func(tx *gorm.DB) error {
+		var hashes []string
+		err := tx.Table("block").Where("number=? and hash<>? and canonical=?", b.Number, b.Hash, true).Pluck("hash", &hashes).Debug().Error
+		if err != nil {
+			return err
+		}
+		for _, hash := range hashes {
+			err = markOrphaned(tx, hash)
+			if err != nil {
+				return err
+			}
+		}
+		var count int64
+		err = tx.Table("block").Where("hash=?", b.Hash).Count(&count).Debug().Error
+		if err != nil {
+			return err
+		}
+		b.Canonical = true
+		if count > 0 {
+			return tx.Table("block").Where("hash=?", b.Hash).Update("canonical", true).Debug().Error
+		}
+		return tx.Table("block").Create(b).Debug().Error
+	})
+}
+
+// MarkOrphaned Mark a reorged block with its transactions and receipts as non-canonical, the api fetches them again
+func (b *Block) MarkOrphaned(hash string) error {
+	return db.Mysql.Transaction(func(tx *gorm.DB) error {
+		return markOrphaned(tx, hash)
+	})
+}
+
+func markOrphaned(tx *gorm.DB, hash string) error {
+	err := tx.Table("block").Where("hash=?", hash).Update("canonical", false).Debug().Error
+	if err != nil {
+		return err
+	}
+	err = tx.Table("transaction").Where("block_hash=?", hash).Update("canonical", false).Debug().Error
+	if err != nil {
+		return err
 	}
+	return tx.Table("receipt").Where("block_hash=?", hash).Update("canonical", false).Debug().Error
 }
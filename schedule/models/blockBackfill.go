@@ -0,0 +1,201 @@
+package models
+
+import (
+	"errors"
+	"pledge-backend/db"
+	"pledge-backend/utils"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// backfill status
+const (
+	BackfillStatusRunning = "running"
+	BackfillStatusSynced  = "synced" // reached the finalized head, following it
+	BackfillStatusDone    = "done"   // reached the end of the range
+	BackfillStatusFailed  = "failed"
+)
+
+// BlockBackfill progress of the backfill of a block range into the block, transaction and receipt tables
+type BlockBackfill struct {
+	Id          int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	StartBlock  uint64 `json:"start_block" gorm:"column:start_block;uniqueIndex:uk_range,priority:1"`
+	EndBlock    uint64 `json:"end_block" gorm:"column:end_block;uniqueIndex:uk_range,priority:2"` // 0 follows the finalized head
+	NextBlock   uint64 `json:"next_block" gorm:"column:next_block"`                               // first block that is not saved yet
+	TargetBlock uint64 `json:"target_block" gorm:"column:target_block"`                           // last block of the latest run
+	Status      string `json:"status" gorm:"column:status;type:varchar(20)"`
+	Error       string `json:"error" gorm:"column:error;type:text"`
+	CreatedAt   string `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   string `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// Transaction transaction of a backfilled block, same table as the transactions the api reads lazily
+type Transaction struct {
+	Id          int             `gorm:"column:id;primaryKey;autoIncrement"`
+	Hash        string          `gorm:"column:hash"`
+	Value       decimal.Decimal `gorm:"column:value;type:NUMERIC(30,0)"`
+	Gas         uint64          `gorm:"column:gas"`
+	GasPrice    decimal.Decimal `gorm:"column:gas_price;type:NUMERIC(30,0)"`
+	Nonce       uint64          `gorm:"column:nonce"`
+	ToHash      string          `gorm:"column:to_hash"`
+	BlockNumber uint64          `gorm:"column:block_number"`
+	BlockHash   string          `gorm:"column:block_hash"`
+	Canonical   bool            `gorm:"column:canonical;default:true"`
+}
+
+// Receipt receipt of a backfilled transaction
+type Receipt struct {
+	Id              int64  `gorm:"column:id;primaryKey;autoIncrement"`
+	Status          uint64 `gorm:"column:status"`
+	TransactionHash string `gorm:"column:transaction_hash"`
+	GasUsed         uint64 `gorm:"column:gas_used"`
+	ContractAddress string `gorm:"column:contract_address"`
+	BlockNumber     uint64 `gorm:"column:block_number"`
+	BlockHash       string `gorm:"column:block_hash"`
+	Type            uint8  `gorm:"column:type"`
+	Canonical       bool   `gorm:"column:canonical;default:true"`
+}
+
+func NewBlockBackfill() *BlockBackfill {
+	return &BlockBackfill{}
+}
+
+func (b *BlockBackfill) TableName() string {
+	return "block_backfill"
+}
+
+func (t *Transaction) TableName() string {
+	return "transaction"
+}
+
+func (r *Receipt) TableName() string {
+	return "receipt"
+}
+
+func NewTransaction(tx *types.Transaction, blockNumber uint64, blockHash string) Transaction {
+	toHash := ""
+	if tx.To() != nil { // contract creation
+		toHash = tx.To().String()
+	}
+	return Transaction{
+		Hash:        tx.Hash().Hex(),
+		Value:       decimal.NewFromBigInt(tx.Value(), 0),
+		Gas:         tx.Gas(),
+		GasPrice:    decimal.NewFromBigInt(tx.GasPrice(), 0),
+		Nonce:       tx.Nonce(),
+		ToHash:      toHash,
+		BlockNumber: blockNumber,
+		BlockHash:   blockHash,
+		Canonical:   true,
+	}
+}
+
+func NewReceipt(receipt *types.Receipt) Receipt {
+	return Receipt{
+		Status:          receipt.Status,
+		TransactionHash: receipt.TxHash.String(),
+		GasUsed:         receipt.GasUsed,
+		ContractAddress: receipt.ContractAddress.String(),
+		BlockNumber:     receipt.BlockNumber.Uint64(),
+		BlockHash:       receipt.BlockHash.String(),
+		Type:            receipt.Type,
+		Canonical:       true,
+	}
+}
+
+// GetBackfill Get the progress of a block range, the bool is false if the range was never backfilled
+func (b *BlockBackfill) GetBackfill(startBlock, endBlock uint64) (BlockBackfill, bool, error) {
+	backfill := BlockBackfill{}
+	err := db.Mysql.Table("block_backfill").Where("start_block=? and end_block=?", startBlock, endBlock).First(&backfill).Debug().Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return backfill, false, nil
+		}
+		return backfill, false, errors.New("record select err " + err.Error())
+	}
+	return backfill, true, nil
+}
+
+// SaveStatus Save the status of a run, a new range starts at its start block
+func (b *BlockBackfill) SaveStatus(startBlock, endBlock, targetBlock uint64, status, errMsg string) error {
+	nowDateTime := utils.GetCurDateTimeFormat()
+	return db.Mysql.Table("block_backfill").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "start_block"}, {Name: "end_block"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"target_block": targetBlock,
+			"status":       status,
+			"error":        errMsg,
+			"updated_at":   nowDateTime,
+		}),
+	}).Create(&BlockBackfill{
+		StartBlock:  startBlock,
+		EndBlock:    endBlock,
+		NextBlock:   startBlock,
+		TargetBlock: targetBlock,
+		Status:      status,
+		Error:       errMsg,
+		CreatedAt:   nowDateTime,
+		UpdatedAt:   nowDateTime,
+	}).Debug().Error
+}
+
+// SaveBatch Save the blocks of a batch with their transactions and receipts and move the cursor in one transaction.
+// Rows the api already cached for the blocks are replaced, other blocks of the same numbers are orphaned.
+func (b *BlockBackfill) SaveBatch(startBlock, endBlock uint64, blocks []Block, transactions []Transaction, receipts []Receipt, nextBlock uint64) error {
+	numbers := make([]uint64, 0, len(blocks))
+	hashes := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		numbers = append(numbers, block.Number)
+		hashes = append(hashes, block.Hash)
+	}
+	return db.Mysql.Transaction(func(tx *gorm.DB) error {
+		if len(blocks) > 0 {
+			var orphans []string
+			err := tx.Table("block").Where("number in ? and hash not in ? and canonical=?", numbers, hashes, true).Pluck("hash", &orphans).Debug().Error
+			if err != nil {
+				return err
+			}
+			for _, hash := range orphans {
+				err = markOrphaned(tx, hash)
+				if err != nil {
+					return err
+				}
+			}
+			err = tx.Where("hash in ?", hashes).Delete(&Block{}).Debug().Error
+			if err != nil {
+				return err
+			}
+			err = tx.Where("block_hash in ?", hashes).Delete(&Transaction{}).Debug().Error
+			if err != nil {
+				return err
+			}
+			err = tx.Where("block_hash in ?", hashes).Delete(&Receipt{}).Debug().Error
+			if err != nil {
+				return err
+			}
+			err = tx.Table("block").CreateInBatches(&blocks, 200).Debug().Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(transactions) > 0 {
+			err := tx.Table("transaction").CreateInBatches(&transactions, 200).Debug().Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(receipts) > 0 {
+			err := tx.Table("receipt").CreateInBatches(&receipts, 200).Debug().Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Table("block_backfill").Where("start_block=? and end_block=?", startBlock, endBlock).Updates(map[string]interface{}{
+			"next_block": nextBlock,
+			"updated_at": utils.GetCurDateTimeFormat(),
+		}).Debug().Error
+	})
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"errors"
+	"pledge-backend/db"
+	"pledge-backend/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	KeeperStatusPending = "pending"
+	KeeperStatusSuccess = "success"
+	KeeperStatusFailed  = "failed"
+)
+
+// KeeperAction settle / finish / liquidate transaction sent by the keeper,
+// one row per pool state transition so the same transition is never submitted twice
+type KeeperAction struct {
+	Id          int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId     string `json:"chain_id" gorm:"column:chain_id;type:varchar(20);uniqueIndex:uk_chain_pool_action,priority:1"`
+	PoolId      int    `json:"pool_id" gorm:"column:pool_id;uniqueIndex:uk_chain_pool_action,priority:2"`
+	Action      string `json:"action" gorm:"column:action;type:varchar(20);uniqueIndex:uk_chain_pool_action,priority:3"`
+	FromState   string `json:"from_state" gorm:"column:from_state;type:varchar(10);uniqueIndex:uk_chain_pool_action,priority:4"`
+	TxHash      string `json:"tx_hash" gorm:"column:tx_hash;type:varchar(66)"`
+	Status      string `json:"status" gorm:"column:status;type:varchar(20)"`
+	Attempts    int    `json:"attempts" gorm:"column:attempts"`
+	MaxAttempts int    `json:"max_attempts" gorm:"column:max_attempts"` // raise it to let the keeper retry a transition it gave up on
+	ErrMsg      string `json:"err_msg" gorm:"column:err_msg;type:text"`
+	CreatedAt   string `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   string `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func NewKeeperAction() *KeeperAction {
+	return &KeeperAction{}
+}
+
+func (k *KeeperAction) TableName() string {
+	return "keeper_actions"
+}
+
+// ClaimAction Reserve a pool state transition, returns false if it is pending or already done.
+// A failed transition is reserved again once retryInterval seconds, doubled per attempt, passed since it failed,
+// until it used its max attempts.
+func (k *KeeperAction) ClaimAction(chainId string, poolId int, action, fromState string, maxAttempts int, retryInterval int64) (*KeeperAction, bool, error) {
+	nowDateTime := utils.GetCurDateTimeFormat()
+	keeperAction := KeeperAction{}
+	err := db.Mysql.Table("keeper_actions").Where("chain_id=? and pool_id=? and action=? and from_state=?", chainId, poolId, action, fromState).First(&keeperAction).Debug().Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, errors.New("record select err " + err.Error())
+		}
+		keeperAction = KeeperAction{
+			ChainId:     chainId,
+			PoolId:      poolId,
+			Action:      action,
+			FromState:   fromState,
+			Status:      KeeperStatusPending,
+			Attempts:    1,
+			MaxAttempts: maxAttempts,
+			CreatedAt:   nowDateTime,
+			UpdatedAt:   nowDateTime,
+		}
+		// the unique key rejects a second keeper that reserves the same transition at the same time
+		err = db.Mysql.Table("keeper_actions").Create(&keeperAction).Debug().Error
+		if err != nil {
+			return nil, false, err
+		}
+		return &keeperAction, true, nil
+	}
+
+	if keeperAction.MaxAttempts == 0 {
+		// saved before the rows had max attempts
+		keeperAction.MaxAttempts = maxAttempts
+	}
+	if keeperAction.Status != KeeperStatusFailed || keeperAction.Attempts >= keeperAction.MaxAttempts {
+		return &keeperAction, false, nil
+	}
+	failedAt := utils.StringToInt64(utils.GetTimeStampByFormat(keeperAction.UpdatedAt))
+	if time.Now().Unix()-failedAt < retryInterval<<(keeperAction.Attempts-1) {
+		return &keeperAction, false, nil
+	}
+
+	res := db.Mysql.Table("keeper_actions").Where("id=? and status=?", keeperAction.Id, KeeperStatusFailed).Updates(map[string]interface{}{
+		"status":       KeeperStatusPending,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"max_attempts": keeperAction.MaxAttempts,
+		"tx_hash":      "",
+		"err_msg":      "",
+		"updated_at":   nowDateTime,
+	}).Debug()
+	if res.Error != nil {
+		return nil, false, res.Error
+	}
+	if res.RowsAffected != 1 {
+		return &keeperAction, false, nil
+	}
+	keeperAction.Status = KeeperStatusPending
+	keeperAction.Attempts++
+	return &keeperAction, true, nil
+}
+
+// SaveTxHash Save the hash of the submitted transaction
+func (k *KeeperAction) SaveTxHash(id int, txHash string) error {
+	return db.Mysql.Table("keeper_actions").Where("id=?", id).Updates(map[string]interface{}{
+		"tx_hash":    txHash,
+		"updated_at": utils.GetCurDateTimeFormat(),
+	}).Debug().Error
+}
+
+// FinishAction Save the outcome of a transition
+func (k *KeeperAction) FinishAction(id int, status, errMsg string) error {
+	return db.Mysql.Table("keeper_actions").Where("id=?", id).Updates(map[string]interface{}{
+		"status":     status,
+		"err_msg":    errMsg,
+		"updated_at": utils.GetCurDateTimeFormat(),
+	}).Debug().Error
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"pledge-backend/db"
+	"pledge-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OracleUpdatePending = "pending"
+	OracleUpdateSuccess = "success"
+	OracleUpdateFailed  = "failed"
+)
+
+const (
+	OracleReasonDeviation = "deviation"
+	OracleReasonHeartbeat = "heartbeat"
+)
+
+// OracleUpdate price of one asset pushed to the oracle, the assets pushed by one SetPrices share the tx hash
+type OracleUpdate struct {
+	Id        int    `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId   string `json:"chain_id" gorm:"column:chain_id;type:varchar(20);index:idx_chain_asset,priority:1"`
+	Asset     string `json:"asset" gorm:"column:asset;type:varchar(42);index:idx_chain_asset,priority:2"`
+	OldPrice  string `json:"old_price" gorm:"column:old_price;type:varchar(80)"` // oracle price before the push
+	NewPrice  string `json:"new_price" gorm:"column:new_price;type:varchar(80)"`
+	Deviation string `json:"deviation" gorm:"column:deviation;type:varchar(40)"` // percent
+	Reason    string `json:"reason" gorm:"column:reason;type:varchar(20)"`
+	TxHash    string `json:"tx_hash" gorm:"column:tx_hash;type:varchar(66);index"`
+	Status    string `json:"status" gorm:"column:status;type:varchar(20)"`
+	ErrMsg    string `json:"err_msg" gorm:"column:err_msg;type:text"`
+	PushedAt  int64  `json:"pushed_at" gorm:"column:pushed_at"` // unix seconds the transaction was sent
+	CreatedAt string `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt string `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func NewOracleUpdate() *OracleUpdate {
+	return &OracleUpdate{}
+}
+
+func (o *OracleUpdate) TableName() string {
+	return "oracle_updates"
+}
+
+// CreateUpdates Log the assets of one push, before it is sent so a sent transaction always has its rows
+func (o *OracleUpdate) CreateUpdates(updates []OracleUpdate) error {
+	nowDateTime := utils.GetCurDateTimeFormat()
+	for i := range updates {
+		updates[i].CreatedAt = nowDateTime
+		updates[i].UpdatedAt = nowDateTime
+	}
+	return db.Mysql.Table("oracle_updates").Create(&updates).Debug().Error
+}
+
+// SaveTxHash Save the hash of the transaction that pushed the assets
+func (o *OracleUpdate) SaveTxHash(ids []int, txHash string) error {
+	return db.Mysql.Table("oracle_updates").Where("id in ?", ids).Updates(map[string]interface{}{
+		"tx_hash":    txHash,
+		"updated_at": utils.GetCurDateTimeFormat(),
+	}).Debug().Error
+}
+
+// LastSuccess Last successful push of an asset, nil if it was never pushed
+func (o *OracleUpdate) LastSuccess(chainId, asset string) (*OracleUpdate, error) {
+	update := OracleUpdate{}
+	err := db.Mysql.Table("oracle_updates").Where("chain_id=? and asset=? and status=?", chainId, asset, OracleUpdateSuccess).
+		Order("id desc").First(&update).Debug().Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &update, nil
+}
+
+// PendingTxHashes Transactions of a chain that were sent but whose receipt was not seen yet
+func (o *OracleUpdate) PendingTxHashes(chainId string) ([]string, error) {
+	txHashes := make([]string, 0)
+	err := db.Mysql.Table("oracle_updates").Where("chain_id=? and status=? and tx_hash<>''", chainId, OracleUpdatePending).
+		Distinct("tx_hash").Pluck("tx_hash", &txHashes).Debug().Error
+	return txHashes, err
+}
+
+// PendingWithoutTxHash Assets of a chain whose push was not sent yet or whose hash was not saved
+func (o *OracleUpdate) PendingWithoutTxHash(chainId string) ([]OracleUpdate, error) {
+	updates := make([]OracleUpdate, 0)
+	err := db.Mysql.Table("oracle_updates").Where("chain_id=? and status=? and tx_hash=''", chainId, OracleUpdatePending).Find(&updates).Debug().Error
+	return updates, err
+}
+
+// ListByTxHash Assets pushed by one transaction
+func (o *OracleUpdate) ListByTxHash(txHash string) ([]OracleUpdate, error) {
+	updates := make([]OracleUpdate, 0)
+	err := db.Mysql.Table("oracle_updates").Where("tx_hash=?", txHash).Find(&updates).Debug().Error
+	return updates, err
+}
+
+// Finish Save the outcome of a pushed asset
+func (o *OracleUpdate) Finish(id int, status, errMsg string) error {
+	return db.Mysql.Table("oracle_updates").Where("id=?", id).Updates(map[string]interface{}{
+		"status":     status,
+		"err_msg":    errMsg,
+		"updated_at": utils.GetCurDateTimeFormat(),
+	}).Debug().Error
+}
@@ -30,6 +30,8 @@ type PoolBase struct {
 	LendTokenSymbol        string `json:"lend_token_symbol" gorm:"column:lend_token_symbol"`
 	BorrowTokenSymbol      string `json:"borrow_token_symbol" gorm:"column:borrow_token_symbol"`
 	AutoLiquidateThreshold string `json:"auto_liquidate_threshold" gorm:"column:auto_liquidate_threshold"`
+	BlockNumber            uint64 `json:"block_number" gorm:"column:block_number"` // block the values were read at
+	BlockHash              string `json:"block_hash" gorm:"column:block_hash;type:varchar(66)"`
 	CreatedAt              string `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt              string `json:"updated_at" gorm:"column:updated_at"`
 }
@@ -96,6 +98,17 @@ func (p *PoolBase) SavePoolBase(chainId, poolId string, poolBase *PoolBase) erro
 	return nil
 }
 
+// SaveBlock Save the block the unchanged pools of a chain were read at, one update for all of them
+func (p *PoolBase) SaveBlock(chainId string, poolIds []string, blockNumber uint64, blockHash string) error {
+	if len(poolIds) == 0 {
+		return nil
+	}
+	return db.Mysql.Table("poolbases").Where("chain_id=? and pool_id in ?", chainId, poolIds).Updates(map[string]interface{}{
+		"block_number": blockNumber,
+		"block_hash":   blockHash,
+	}).Debug().Error
+}
+
 func (p *PoolBase) PoolBaseInfo(res *PoolBase) error {
 	err := db.Mysql.Table("poolbases").Order("pool_id asc").Find(&res).Debug().Error
 	if err != nil {
@@ -105,6 +118,16 @@ func (p *PoolBase) PoolBaseInfo(res *PoolBase) error {
 	return nil
 }
 
+// GetPoolsByState Get the pools of a chain in the given state
+func (p *PoolBase) GetPoolsByState(chainId, state string) ([]PoolBase, error) {
+	var pools []PoolBase
+	err := db.Mysql.Table("poolbases").Where("chain_id=? and state=?", chainId, state).Order("pool_id asc").Find(&pools).Debug().Error
+	if err != nil {
+		return nil, err
+	}
+	return pools, nil
+}
+
 func (p *PoolBase) SaveTokenInfo(base *PoolBase) (error, []string) {
 	tokenInfo := TokenInfo{}
 	tokenSymbol := []string{"", ""}
@@ -17,6 +17,8 @@ type PoolData struct {
 	LiquidationAmounLend   string `json:"liquidation_amoun_lend" gorm:"column:liquidation_amoun_lend"`
 	SettleAmountBorrow     string `json:"settle_amount_borrow" gorm:"column:settle_amount_borrow"`
 	SettleAmountLend       string `json:"settle_amount_lend" gorm:"column:settle_amount_lend"`
+	BlockNumber            uint64 `json:"block_number" gorm:"column:block_number"` // block the values were read at
+	BlockHash              string `json:"block_hash" gorm:"column:block_hash;type:varchar(66)"`
 	CreatedAt              string `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt              string `json:"updated_at" gorm:"column:updated_at"`
 }
@@ -49,3 +51,24 @@ func (t *PoolData) SavePoolData(chainId, poolId string, poolData *PoolData) erro
 	}
 	return nil
 }
+
+// SaveBlock Save the block the unchanged pool data of a chain was read at, one update for all of them
+func (t *PoolData) SaveBlock(chainId string, poolIds []string, blockNumber uint64, blockHash string) error {
+	if len(poolIds) == 0 {
+		return nil
+	}
+	return db.Mysql.Table("pooldata").Where("chain_id=? and pool_id in ?", chainId, poolIds).Updates(map[string]interface{}{
+		"block_number": blockNumber,
+		"block_hash":   blockHash,
+	}).Debug().Error
+}
+
+// GetPoolData Get poolData information of one pool
+func (t *PoolData) GetPoolData(chainId, poolId string) (PoolData, error) {
+	poolData := PoolData{}
+	err := db.Mysql.Table("pooldata").Where("chain_id=? and pool_id=?", chainId, poolId).First(&poolData).Debug().Error
+	if err != nil {
+		return poolData, err
+	}
+	return poolData, nil
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"pledge-backend/db"
+	"pledge-backend/utils"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PoolEvent decoded PledgePool contract log
+type PoolEvent struct {
+	Id              int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId         string `json:"chain_id" gorm:"column:chain_id;type:varchar(20);uniqueIndex:uk_chain_tx_log,priority:1"`
+	ContractAddress string `json:"contract_address" gorm:"column:contract_address;type:varchar(42)"`
+	EventName       string `json:"event_name" gorm:"column:event_name;type:varchar(50);index"`
+	BlockNumber     uint64 `json:"block_number" gorm:"column:block_number;index"`
+	BlockHash       string `json:"block_hash" gorm:"column:block_hash;type:varchar(66)"`
+	TxHash          string `json:"tx_hash" gorm:"column:tx_hash;type:varchar(66);uniqueIndex:uk_chain_tx_log,priority:2"`
+	LogIndex        uint   `json:"log_index" gorm:"column:log_index;uniqueIndex:uk_chain_tx_log,priority:3"`
+	Account         string `json:"account" gorm:"column:account;type:varchar(42);index"` // from / recieptor
+	Token           string `json:"token" gorm:"column:token;type:varchar(42)"`           // lend or borrow token, fromCoin on swap
+	ToToken         string `json:"to_token" gorm:"column:to_token;type:varchar(42)"`     // toCoin on swap
+	Amount          string `json:"amount" gorm:"column:amount"`                          // amount / refund / fromValue
+	ExtraAmount     string `json:"extra_amount" gorm:"column:extra_amount"`              // mintAmount / burnAmount / toValue
+	PoolId          int    `json:"pool_id" gorm:"column:pool_id"`                        // only StateChange carries the pid
+	BeforeState     string `json:"before_state" gorm:"column:before_state"`
+	AfterState      string `json:"after_state" gorm:"column:after_state"`
+	CreatedAt       string `json:"created_at" gorm:"column:created_at"`
+}
+
+// EventCursor last indexed block per chain and contract
+type EventCursor struct {
+	Id              int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId         string `json:"chain_id" gorm:"column:chain_id;type:varchar(20);uniqueIndex:uk_chain_contract,priority:1"`
+	ContractAddress string `json:"contract_address" gorm:"column:contract_address;type:varchar(42);uniqueIndex:uk_chain_contract,priority:2"`
+	LastBlock       uint64 `json:"last_block" gorm:"column:last_block"`
+	CreatedAt       string `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt       string `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func NewPoolEvent() *PoolEvent {
+	return &PoolEvent{}
+}
+
+func (e *PoolEvent) TableName() string {
+	return "pool_events"
+}
+
+func NewEventCursor() *EventCursor {
+	return &EventCursor{}
+}
+
+func (c *EventCursor) TableName() string {
+	return "event_cursor"
+}
+
+// NewPoolEventFromLog fill the common log fields of an event
+func NewPoolEventFromLog(chainId, contractAddress, eventName string, raw types.Log) PoolEvent {
+	return PoolEvent{
+		ChainId:         chainId,
+		ContractAddress: contractAddress,
+		EventName:       eventName,
+		BlockNumber:     raw.BlockNumber,
+		BlockHash:       raw.BlockHash.String(),
+		TxHash:          raw.TxHash.String(),
+		LogIndex:        raw.Index,
+	}
+}
+
+// BigIntString nil safe big.Int to string
+func BigIntString(n *big.Int) string {
+	if n == nil {
+		return "0"
+	}
+	return n.String()
+}
+
+// GetLastBlock Get the last indexed block, the bool is false if the contract was never indexed
+func (c *EventCursor) GetLastBlock(chainId, contractAddress string) (uint64, bool, error) {
+	cursor := EventCursor{}
+	err := db.Mysql.Table("event_cursor").Where("chain_id=? and contract_address=?", chainId, contractAddress).First(&cursor).Debug().Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, errors.New("record select err " + err.Error())
+	}
+	return cursor.LastBlock, true, nil
+}
+
+// SaveEvents Save the events of a block range and move the cursor in one transaction
+func (e *PoolEvent) SaveEvents(chainId, contractAddress string, events []PoolEvent, lastBlock uint64) error {
+	nowDateTime := utils.GetCurDateTimeFormat()
+	return db.Mysql.Transaction(func(tx *gorm.DB) error {
+		if len(events) > 0 {
+			for i := range events {
+				events[i].CreatedAt = nowDateTime
+			}
+			// the unique key (chain_id, tx_hash, log_index) makes a replayed range a no-op
+			err := tx.Table("pool_events").Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&events, 200).Debug().Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err := tx.Table("event_cursor").Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chain_id"}, {Name: "contract_address"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"last_block": lastBlock, "updated_at": nowDateTime}),
+		}).Create(&EventCursor{
+			ChainId:         chainId,
+			ContractAddress: contractAddress,
+			LastBlock:       lastBlock,
+			CreatedAt:       nowDateTime,
+			UpdatedAt:       nowDateTime,
+		}).Debug().Error
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
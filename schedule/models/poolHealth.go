@@ -0,0 +1,41 @@
+package models
+
+import (
+	"pledge-backend/db"
+	"pledge-backend/utils"
+)
+
+// PoolHealth collateral health of a pool in execution state, one row per check
+type PoolHealth struct {
+	Id                 int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId            string `json:"chain_id" gorm:"column:chain_id;type:varchar(20);index:idx_chain_pool,priority:1"`
+	PoolId             int    `json:"pool_id" gorm:"column:pool_id;index:idx_chain_pool,priority:2"`
+	LendPrice          string `json:"lend_price" gorm:"column:lend_price"`
+	BorrowPrice        string `json:"borrow_price" gorm:"column:borrow_price"`
+	SettleAmountLend   string `json:"settle_amount_lend" gorm:"column:settle_amount_lend"`
+	SettleAmountBorrow string `json:"settle_amount_borrow" gorm:"column:settle_amount_borrow"`
+	MartgageRate       string `json:"martgage_rate" gorm:"column:martgage_rate"`
+	LiquidateRatio     string `json:"liquidate_ratio" gorm:"column:liquidate_ratio"`   // 1 + autoLiquidateThreshold
+	CollateralRatio    string `json:"collateral_ratio" gorm:"column:collateral_ratio"` // borrow value / settled lend amount
+	Health             string `json:"health" gorm:"column:health"`                     // collateral ratio / liquidate ratio, liquidation below 1
+	Band               int    `json:"band" gorm:"column:band"`                         // number of warning bands crossed
+	CreatedAt          string `json:"created_at" gorm:"column:created_at;index"`
+}
+
+func NewPoolHealth() *PoolHealth {
+	return &PoolHealth{}
+}
+
+func (h *PoolHealth) TableName() string {
+	return "pool_health"
+}
+
+// SavePoolHealth Save a health check result
+func (h *PoolHealth) SavePoolHealth(poolHealth *PoolHealth) error {
+	poolHealth.CreatedAt = utils.GetCurDateTimeFormat()
+	err := db.Mysql.Table("pool_health").Create(poolHealth).Debug().Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
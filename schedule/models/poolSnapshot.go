@@ -0,0 +1,62 @@
+package models
+
+import (
+	"pledge-backend/db"
+	"pledge-backend/utils"
+)
+
+// PoolSnapshot pool base and data information at one point in time, one row per detected change
+type PoolSnapshot struct {
+	Id                     int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId                string `json:"chain_id" gorm:"column:chain_id;type:varchar(20);index:idx_chain_pool_time,priority:1"`
+	PoolId                 int    `json:"pool_id" gorm:"column:pool_id;index:idx_chain_pool_time,priority:2"`
+	SnapshotTime           int64  `json:"snapshot_time" gorm:"column:snapshot_time;index:idx_chain_pool_time,priority:3"` // unix seconds
+	State                  string `json:"state" gorm:"column:state"`
+	InterestRate           string `json:"interest_rate" gorm:"column:interest_rate"`
+	MaxSupply              string `json:"max_supply" gorm:"column:max_supply"`
+	LendSupply             string `json:"lend_supply" gorm:"column:lend_supply"`
+	BorrowSupply           string `json:"borrow_supply" gorm:"column:borrow_supply"`
+	FinishAmountBorrow     string `json:"finish_amount_borrow" gorm:"column:finish_amount_borrow"`
+	FinishAmountLend       string `json:"finish_amount_lend" gorm:"column:finish_amount_lend"`
+	LiquidationAmounBorrow string `json:"liquidation_amoun_borrow" gorm:"column:liquidation_amoun_borrow"`
+	LiquidationAmounLend   string `json:"liquidation_amoun_lend" gorm:"column:liquidation_amoun_lend"`
+	SettleAmountBorrow     string `json:"settle_amount_borrow" gorm:"column:settle_amount_borrow"`
+	SettleAmountLend       string `json:"settle_amount_lend" gorm:"column:settle_amount_lend"`
+	BlockNumber            uint64 `json:"block_number" gorm:"column:block_number"`
+	CreatedAt              string `json:"created_at" gorm:"column:created_at"`
+}
+
+func NewPoolSnapshot() *PoolSnapshot {
+	return &PoolSnapshot{}
+}
+
+func (p *PoolSnapshot) TableName() string {
+	return "pool_snapshots"
+}
+
+// SavePoolSnapshot Append a snapshot of poolBase and poolData
+func (p *PoolSnapshot) SavePoolSnapshot(poolBase *PoolBase, poolData *PoolData, snapshotTime int64) error {
+	poolSnapshot := PoolSnapshot{
+		ChainId:                poolBase.ChainId,
+		PoolId:                 poolBase.PoolId,
+		SnapshotTime:           snapshotTime,
+		State:                  poolBase.State,
+		InterestRate:           poolBase.InterestRate,
+		MaxSupply:              poolBase.MaxSupply,
+		LendSupply:             poolBase.LendSupply,
+		BorrowSupply:           poolBase.BorrowSupply,
+		FinishAmountBorrow:     poolData.FinishAmountBorrow,
+		FinishAmountLend:       poolData.FinishAmountLend,
+		LiquidationAmounBorrow: poolData.LiquidationAmounBorrow,
+		LiquidationAmounLend:   poolData.LiquidationAmounLend,
+		SettleAmountBorrow:     poolData.SettleAmountBorrow,
+		SettleAmountLend:       poolData.SettleAmountLend,
+		BlockNumber:            poolBase.BlockNumber,
+		CreatedAt:              utils.GetCurDateTimeFormat(),
+	}
+	err := db.Mysql.Table("pool_snapshots").Create(&poolSnapshot).Debug().Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -7,4 +7,13 @@ func InitTable() {
 	db.Mysql.AutoMigrate(&PoolData{})
 	db.Mysql.AutoMigrate(&RedisTokenInfo{})
 	db.Mysql.AutoMigrate(&TokenInfo{})
+	db.Mysql.AutoMigrate(&PoolEvent{})
+	db.Mysql.AutoMigrate(&EventCursor{})
+	db.Mysql.AutoMigrate(&PoolHealth{})
+	db.Mysql.AutoMigrate(&KeeperAction{})
+	db.Mysql.AutoMigrate(&PoolSnapshot{})
+	db.Mysql.AutoMigrate(&TokenPriceSource{})
+	db.Mysql.AutoMigrate(&OracleUpdate{})
+	db.Mysql.AutoMigrate(&TokenPrice{})
+	db.Mysql.AutoMigrate(&BlockBackfill{})
 }
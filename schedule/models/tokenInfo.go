@@ -8,16 +8,18 @@ import (
 )
 
 type TokenInfo struct {
-	Id           int    `gorm:"column:id;primaryKey"`
-	Logo         string `json:"logo" gorm:"column:logo"`
-	Token        string `json:"token" gorm:"column:token"`
-	Symbol       string `json:"symbol" gorm:"column:symbol"`
-	ChainId      string `json:"chain_id" gorm:"column:chain_id"`
-	Price        string `json:"price" gorm:"column:price"`
-	Decimals     int    `json:"decimals" gorm:"column:decimals"`
-	AbiFileExist int    `json:"abi_file_exist" gorm:"column:abi_file_exist"`
-	CreatedAt    string `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt    string `json:"updated_at" gorm:"column:updated_at"`
+	Id          int    `gorm:"column:id;primaryKey"`
+	Logo        string `json:"logo" gorm:"column:logo"`
+	Token       string `json:"token" gorm:"column:token"`
+	Symbol      string `json:"symbol" gorm:"column:symbol"`
+	Name        string `json:"name" gorm:"column:name"`
+	ChainId     string `json:"chain_id" gorm:"column:chain_id"`
+	Price       string `json:"price" gorm:"column:price"`
+	Decimals    int    `json:"decimals" gorm:"column:decimals"`
+	BlockNumber uint64 `json:"block_number" gorm:"column:block_number"` // block the oracle price was read at
+	BlockHash   string `json:"block_hash" gorm:"column:block_hash;type:varchar(66)"`
+	CreatedAt   string `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   string `json:"updated_at" gorm:"column:updated_at"`
 }
 
 func NewTokenInfo() *TokenInfo {
@@ -63,3 +65,11 @@ func (t *TokenInfo) GetTokenInfo(token, chainId string) (error, TokenInfo) {
 	}
 
 }
+
+// SaveBlock Save the block an unchanged token price was read at
+func (t *TokenInfo) SaveBlock(chainId, token string, blockNumber uint64, blockHash string) error {
+	return db.Mysql.Table("token_info").Where("token=? and chain_id=?", token, chainId).Updates(map[string]interface{}{
+		"block_number": blockNumber,
+		"block_hash":   blockHash,
+	}).Debug().Error
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"errors"
+	"pledge-backend/db"
+	"pledge-backend/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// token price resolutions, seconds covered by one row
+const (
+	PriceResolutionRaw  = 0 // one observed price change, open = high = low = close
+	PriceResolutionHour = 3600
+	PriceResolutionDay  = 24 * 3600
+)
+
+// TokenPrice price history of a token, observed changes are merged into hourly and then daily candles as they age
+type TokenPrice struct {
+	Id         int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId    string `json:"chain_id" gorm:"column:chain_id;type:varchar(20);index:idx_chain_token_time,priority:1"`
+	Token      string `json:"token" gorm:"column:token;type:varchar(42);index:idx_chain_token_time,priority:2"`
+	PriceTime  int64  `json:"price_time" gorm:"column:price_time;index:idx_chain_token_time,priority:3"` // unix seconds, candle start
+	Resolution int64  `json:"resolution" gorm:"column:resolution"`
+	Open       string `json:"open" gorm:"column:open;type:varchar(80)"` // usd * 1e8, as the oracle
+	High       string `json:"high" gorm:"column:high;type:varchar(80)"`
+	Low        string `json:"low" gorm:"column:low;type:varchar(80)"`
+	Close      string `json:"close" gorm:"column:close;type:varchar(80)"`
+	CreatedAt  string `json:"created_at" gorm:"column:created_at"`
+}
+
+func NewTokenPrice() *TokenPrice {
+	return &TokenPrice{}
+}
+
+func (t *TokenPrice) TableName() string {
+	return "token_prices"
+}
+
+// SaveTokenPrice Append an observed price, unless it is the last known price of the token
+func (t *TokenPrice) SaveTokenPrice(chainId, token, price string, priceTime int64) error {
+	last := TokenPrice{}
+	err := db.Mysql.Table("token_prices").Where("chain_id=? and token=?", chainId, token).
+		Order("price_time desc, id desc").First(&last).Debug().Error
+	if err == nil && last.Close == price {
+		return nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return db.Mysql.Table("token_prices").Create(&TokenPrice{
+		ChainId:    chainId,
+		Token:      token,
+		PriceTime:  priceTime,
+		Resolution: PriceResolutionRaw,
+		Open:       price,
+		High:       price,
+		Low:        price,
+		Close:      price,
+		CreatedAt:  utils.GetCurDateTimeFormat(),
+	}).Debug().Error
+}
+
+// Downsample Merge the rows of resolution from that start before cutoff into candles of resolution to.
+// cutoff is rounded down to a candle start so a candle is only built once all its rows are old enough.
+func (t *TokenPrice) Downsample(from, to, cutoff int64) error {
+	cutoff -= cutoff % to
+	type tokenKey struct {
+		ChainId string
+		Token   string
+	}
+	var keys []tokenKey
+	err := db.Mysql.Table("token_prices").Select("distinct chain_id, token").
+		Where("resolution=? and price_time<?", from, cutoff).Scan(&keys).Debug().Error
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		err = db.Mysql.Transaction(func(tx *gorm.DB) error {
+			var rows []TokenPrice
+			err := tx.Table("token_prices").Where("chain_id=? and token=? and resolution=? and price_time<?", key.ChainId, key.Token, from, cutoff).
+				Order("price_time asc, id asc").Find(&rows).Debug().Error
+			if err != nil {
+				return err
+			}
+			candles := mergeCandles(rows, to)
+			nowDateTime := utils.GetCurDateTimeFormat()
+			for i := range candles {
+				candles[i].CreatedAt = nowDateTime
+			}
+			err = tx.Table("token_prices").CreateInBatches(&candles, 200).Debug().Error
+			if err != nil {
+				return err
+			}
+			return tx.Table("token_prices").Where("chain_id=? and token=? and resolution=? and price_time<?", key.ChainId, key.Token, from, cutoff).
+				Delete(&TokenPrice{}).Debug().Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeCandles merge rows ordered by time into candles of the given resolution
+func mergeCandles(rows []TokenPrice, resolution int64) []TokenPrice {
+	candles := make([]TokenPrice, 0)
+	for _, row := range rows {
+		bucket := row.PriceTime - row.PriceTime%resolution
+		n := len(candles)
+		if n == 0 || candles[n-1].PriceTime != bucket {
+			candles = append(candles, TokenPrice{
+				ChainId:    row.ChainId,
+				Token:      row.Token,
+				PriceTime:  bucket,
+				Resolution: resolution,
+				Open:       row.Open,
+				High:       row.High,
+				Low:        row.Low,
+				Close:      row.Close,
+			})
+			continue
+		}
+		candle := &candles[n-1]
+		rowHigh, _ := decimal.NewFromString(row.High)
+		candleHigh, _ := decimal.NewFromString(candle.High)
+		if rowHigh.GreaterThan(candleHigh) {
+			candle.High = row.High
+		}
+		rowLow, _ := decimal.NewFromString(row.Low)
+		candleLow, _ := decimal.NewFromString(candle.Low)
+		if rowLow.LessThan(candleLow) {
+			candle.Low = row.Low
+		}
+		candle.Close = row.Close
+	}
+	return candles
+}
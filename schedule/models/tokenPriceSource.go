@@ -0,0 +1,50 @@
+package models
+
+import (
+	"pledge-backend/db"
+	"pledge-backend/utils"
+
+	"gorm.io/gorm/clause"
+)
+
+const (
+	PriceSourceAccepted = "accepted" // part of the aggregated price
+	PriceSourceRejected = "rejected" // too far from the median
+	PriceSourceFailed   = "failed"   // the source returned an error
+)
+
+// TokenPriceSource last price of a token from one source and whether the aggregation used it
+type TokenPriceSource struct {
+	Id        int    `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	ChainId   string `json:"chain_id" gorm:"column:chain_id;type:varchar(20);uniqueIndex:uk_chain_token_source,priority:1"`
+	Token     string `json:"token" gorm:"column:token;type:varchar(42);uniqueIndex:uk_chain_token_source,priority:2"`
+	Source    string `json:"source" gorm:"column:source;type:varchar(50);uniqueIndex:uk_chain_token_source,priority:3"`
+	Price     string `json:"price" gorm:"column:price;type:varchar(80)"`         // usd * 1e8, as the oracle
+	Deviation string `json:"deviation" gorm:"column:deviation;type:varchar(40)"` // percent from the median of all sources
+	Status    string `json:"status" gorm:"column:status;type:varchar(20)"`
+	ErrMsg    string `json:"err_msg" gorm:"column:err_msg;type:text"`
+	UpdatedAt string `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func NewTokenPriceSource() *TokenPriceSource {
+	return &TokenPriceSource{}
+}
+
+func (t *TokenPriceSource) TableName() string {
+	return "token_price_sources"
+}
+
+// SaveSources Replace the last price of every source of a token
+func (t *TokenPriceSource) SaveSources(sources []TokenPriceSource) error {
+	if len(sources) == 0 {
+		return nil
+	}
+	nowDateTime := utils.GetCurDateTimeFormat()
+	for i := range sources {
+		sources[i].UpdatedAt = nowDateTime
+	}
+	return db.Mysql.Table("token_price_sources").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain_id"}, {Name: "token"}, {Name: "source"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "deviation", "status", "err_msg", "updated_at"}),
+	}).Create(&sources).Debug().Error
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeCandles(t *testing.T) {
+	row := func(priceTime int64, open, high, low, close string) TokenPrice {
+		return TokenPrice{ChainId: "97", Token: "0xtoken", PriceTime: priceTime, Resolution: 60, Open: open, High: high, Low: low, Close: close}
+	}
+	candle := func(priceTime int64, open, high, low, close string) TokenPrice {
+		return TokenPrice{ChainId: "97", Token: "0xtoken", PriceTime: priceTime, Resolution: 3600, Open: open, High: high, Low: low, Close: close}
+	}
+	tests := []struct {
+		name string
+		rows []TokenPrice
+		want []TokenPrice
+	}{
+		{name: "no rows", rows: nil, want: []TokenPrice{}},
+		{
+			name: "one row",
+			rows: []TokenPrice{row(3660, "10", "12", "9", "11")},
+			want: []TokenPrice{candle(3600, "10", "12", "9", "11")},
+		},
+		{
+			name: "rows of one bucket",
+			rows: []TokenPrice{
+				row(3600, "10", "12", "9", "11"),
+				row(3660, "11", "15", "10", "14"),
+				row(7140, "14", "14", "8", "9"),
+			},
+			want: []TokenPrice{candle(3600, "10", "15", "8", "9")},
+		},
+		{
+			name: "high and low compare as numbers",
+			rows: []TokenPrice{
+				row(3600, "9", "9", "9", "9"),
+				row(3660, "9", "10", "10", "10"),
+			},
+			want: []TokenPrice{candle(3600, "9", "10", "9", "10")},
+		},
+		{
+			name: "rows of two buckets with a gap",
+			rows: []TokenPrice{
+				row(3600, "10", "12", "9", "11"),
+				row(7200, "11", "13", "10", "12"),
+				row(14400, "20", "21", "19", "20"),
+			},
+			want: []TokenPrice{
+				candle(3600, "10", "12", "9", "11"),
+				candle(7200, "11", "13", "10", "12"),
+				candle(14400, "20", "21", "19", "20"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeCandles(tt.rows, 3600)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeCandles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"pledge-backend/chainclient"
 	"pledge-backend/config"
 	"pledge-backend/log"
 	"pledge-backend/utils"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/shopspring/decimal"
 )
 
@@ -23,46 +23,36 @@ func NewBalanceMonitor() *BalanceMonitor {
 // Monitor Sending email when balance is insufficient
 func (s *BalanceMonitor) Monitor() {
 
-	//check on bsc test-net
-	tokenPoolBalance, err := s.GetBalance(config.Config.TestNet.NetUrl, config.Config.TestNet.PledgePoolToken)
 	thresholdPoolToken, ok := new(big.Int).SetString(config.Config.Threshold.PledgePoolTokenThresholdBnb, 10)
-	if ok && (err == nil) && (tokenPoolBalance.Cmp(thresholdPoolToken) <= 0) {
-		emailBody, err := s.EmailBody(config.Config.TestNet.PledgePoolToken, "TBNB", tokenPoolBalance.String(), thresholdPoolToken.String())
-		if err != nil {
-			log.Logger.Error(err.Error())
-		} else {
-			err = utils.SendEmail(emailBody, 2)
+	if !ok {
+		log.Logger.Sugar().Error("BalanceMonitor threshold err ", config.Config.Threshold.PledgePoolTokenThresholdBnb)
+		return
+	}
+
+	for _, chain := range config.EnabledChains() {
+		tokenPoolBalance, err := s.GetBalance(chain.ChainId, chain.PledgePoolToken)
+		if (err == nil) && (tokenPoolBalance.Cmp(thresholdPoolToken) <= 0) {
+			emailBody, err := s.EmailBody(chain.PledgePoolToken, chain.NativeSymbol, tokenPoolBalance.String(), thresholdPoolToken.String())
 			if err != nil {
 				log.Logger.Error(err.Error())
+			} else {
+				err = utils.SendEmail(emailBody, 2)
+				if err != nil {
+					log.Logger.Error(err.Error())
+				}
 			}
 		}
 	}
-
-	//check on bsc main-net
-	// tokenPoolBalance, err = s.GetBalance(config.Config.MainNet.NetUrl, config.Config.MainNet.PledgePoolToken)
-	// thresholdPoolToken, ok = new(big.Int).SetString(config.Config.Threshold.PledgePoolTokenThresholdBnb, 10)
-	// if ok && (err == nil) && (tokenPoolBalance.Cmp(thresholdPoolToken) <= 0) {
-	// 	emailBody, err := s.EmailBody(config.Config.MainNet.PledgePoolToken, "BNB", tokenPoolBalance.String(), thresholdPoolToken.String())
-	// 	if err != nil {
-	// 		log.Logger.Error(err.Error())
-	// 	} else {
-	// 		err = utils.SendEmail(emailBody, 2)
-	// 		if err != nil {
-	// 			log.Logger.Error(err.Error())
-	// 		}
-	// 	}
-	// }
 }
 
 // GetBalance get balance of ERC20 token
-func (s *BalanceMonitor) GetBalance(netUrl, token string) (*big.Int, error) {
+func (s *BalanceMonitor) GetBalance(chainId, token string) (*big.Int, error) {
 
-	ethereumClient, err := ethclient.Dial(netUrl)
+	ethereumClient, err := chainclient.GetClient(chainId)
 	if err != nil {
 		log.Logger.Error(err.Error())
 		return big.NewInt(0), err
 	}
-	defer ethereumClient.Close()
 
 	balance, err := ethereumClient.BalanceAt(context.Background(), common.HexToAddress(token), nil)
 	if err != nil {
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"pledge-backend/chainclient"
+	"pledge-backend/config"
+	"pledge-backend/log"
+	"pledge-backend/schedule/models"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type BlockBackfill struct{}
+
+// backfillMu a run can take longer than the job interval, the next run is skipped until it ended
+var backfillMu sync.Mutex
+
+func NewBlockBackfill() *BlockBackfill {
+	return &BlockBackfill{}
+}
+
+// Run Backfill the configured block range into the block, transaction and receipt tables.
+// A run resumes at the saved cursor and ends at the end of the range or at the finalized head,
+// the blocks after the finalized head are left to the api and the reorg check.
+func (s *BlockBackfill) Run() {
+	conf := config.Config.Backfill
+	if !conf.Enabled {
+		return
+	}
+	if !backfillMu.TryLock() {
+		return
+	}
+	defer backfillMu.Unlock()
+
+	client, err := chainclient.GetStudyClient()
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return
+	}
+
+	backfill, hasCursor, err := models.NewBlockBackfill().GetBackfill(conf.StartBlock, conf.EndBlock)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return
+	}
+	next := conf.StartBlock
+	if hasCursor {
+		next = backfill.NextBlock
+	}
+
+	finalized, err := client.HeaderByNumber(context.Background(), big.NewInt(rpc.FinalizedBlockNumber.Int64()))
+	if err != nil {
+		log.Logger.Sugar().Error("BlockBackfill finalized head err ", err)
+		s.saveStatus(backfill.TargetBlock, models.BackfillStatusFailed, err.Error())
+		return
+	}
+	target := finalized.Number.Uint64()
+	if conf.EndBlock > 0 && conf.EndBlock < target {
+		target = conf.EndBlock
+	}
+
+	batchSize := conf.BatchSize
+	if batchSize == 0 {
+		batchSize = 50
+	}
+	workers := conf.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+
+	if next <= target {
+		s.saveStatus(target, models.BackfillStatusRunning, "")
+	}
+	for next <= target {
+		to := next + batchSize - 1
+		if to > target {
+			to = target
+		}
+
+		blocks, transactions, receipts, err := s.FetchBlocks(client, next, to, workers)
+		if err != nil {
+			log.Logger.Sugar().Error("BlockBackfill FetchBlocks err ", next, "-", to, " ", err)
+			s.saveStatus(target, models.BackfillStatusFailed, err.Error())
+			return
+		}
+		err = models.NewBlockBackfill().SaveBatch(conf.StartBlock, conf.EndBlock, blocks, transactions, receipts, to+1)
+		if err != nil {
+			log.Logger.Sugar().Error("BlockBackfill SaveBatch err ", next, "-", to, " ", err)
+			s.saveStatus(target, models.BackfillStatusFailed, err.Error())
+			return
+		}
+		next = to + 1
+	}
+
+	status := models.BackfillStatusSynced
+	if conf.EndBlock > 0 && next > conf.EndBlock {
+		status = models.BackfillStatusDone
+	}
+	s.saveStatus(target, status, "")
+}
+
+func (s *BlockBackfill) saveStatus(target uint64, status, errMsg string) {
+	conf := config.Config.Backfill
+	err := models.NewBlockBackfill().SaveStatus(conf.StartBlock, conf.EndBlock, target, status, errMsg)
+	if err != nil {
+		log.Logger.Sugar().Error("BlockBackfill SaveStatus err ", err)
+	}
+}
+
+// FetchBlocks Read the blocks of a range with their transactions and receipts, workers blocks at the same time.
+// The rows are returned in block order, the whole range fails if one block could not be read.
+func (s *BlockBackfill) FetchBlocks(client *chainclient.Client, from, to uint64, workers int) ([]models.Block, []models.Transaction, []models.Receipt, error) {
+	type fetched struct {
+		block    *types.Block
+		receipts []*types.Receipt
+		err      error
+	}
+	results := make([]fetched, to-from+1)
+
+	numbers := make(chan uint64)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range numbers {
+				result := &results[number-from]
+				result.block, result.receipts, result.err = s.fetchBlock(client, number)
+			}
+		}()
+	}
+	for number := from; number <= to; number++ {
+		numbers <- number
+	}
+	close(numbers)
+	wg.Wait()
+
+	blocks := make([]models.Block, 0, len(results))
+	transactions := make([]models.Transaction, 0)
+	receipts := make([]models.Receipt, 0)
+	for _, result := range results {
+		if result.err != nil {
+			return nil, nil, nil, result.err
+		}
+		block := models.NewBlock(result.block)
+		blocks = append(blocks, *block)
+		for _, tx := range result.block.Transactions() {
+			transactions = append(transactions, models.NewTransaction(tx, block.Number, block.Hash))
+		}
+		for _, receipt := range result.receipts {
+			receipts = append(receipts, models.NewReceipt(receipt))
+		}
+	}
+	return blocks, transactions, receipts, nil
+}
+
+// fetchBlock Read a block and the receipts of its transactions
+func (s *BlockBackfill) fetchBlock(client *chainclient.Client, number uint64) (*types.Block, []*types.Receipt, error) {
+	block, err := client.BlockByNumber(context.Background(), new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, nil, err
+	}
+	if block.Transactions().Len() == 0 {
+		return block, nil, nil
+	}
+
+	receipts, err := client.BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithHash(block.Hash(), true))
+	if err == nil {
+		return block, receipts, nil
+	}
+	// eth_getBlockReceipts is not served by every node, the receipts are read one by one
+	receipts = make([]*types.Receipt, 0, block.Transactions().Len())
+	for _, tx := range block.Transactions() {
+		receipt, err := client.TransactionReceipt(context.Background(), tx.Hash())
+		if err != nil {
+			return nil, nil, err
+		}
+		receipts = append(receipts, receipt)
+	}
+	return block, receipts, nil
+}
@@ -4,45 +4,83 @@ import (
 	"context"
 	"math/big"
 	"pledge-backend/api/common"
-	"pledge-backend/config"
+	"pledge-backend/chainclient"
 	"pledge-backend/db"
 	"pledge-backend/log"
 	"pledge-backend/schedule/models"
+	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// MaxReorgDepth blocks behind a new head that are checked for a reorg
+const MaxReorgDepth = 128
+
 type EthService struct {
 }
 
+var (
+	reorgMu  sync.Mutex
+	lastHead string // head the cached blocks were last checked against
+)
+
+// reorgChain the chain reads of a reorg check
+type reorgChain interface {
+	HeaderByHash(ctx context.Context, hash ethCommon.Hash) (*types.Header, error)
+	BlockByHash(ctx context.Context, hash ethCommon.Hash) (*types.Block, error)
+}
+
+// reorgStore the cached blocks a reorg check compares with the chain
+type reorgStore interface {
+	CanonicalBlocksFrom(from uint64) (map[uint64]models.Block, error)
+	MarkOrphaned(hash string) error
+	Save(block *types.Block) error
+}
+
+// blockStore the block table
+type blockStore struct{}
+
+func (blockStore) CanonicalBlocksFrom(from uint64) (map[uint64]models.Block, error) {
+	return (&models.Block{}).CanonicalBlocksFrom(from)
+}
+
+func (blockStore) MarkOrphaned(hash string) error {
+	return (&models.Block{}).MarkOrphaned(hash)
+}
+
+func (blockStore) Save(block *types.Block) error {
+	return models.NewBlock(block).Save()
+}
+
 func NewEthService() *EthService {
 	return &EthService{}
 }
 
 func (s *EthService) GetBlock() {
 
-	client, err := ethclient.Dial(config.Config.TestNet.TestEthUrl)
+	client, err := chainclient.GetStudyClient()
 	if err != nil {
 		log.Logger.Error(err.Error())
 		return
 	}
-	defer client.Close()
 
 	// 建立定时任务
 	ticker := time.NewTicker(time.Minute * 1)
-	// 需要遍历获取的区块
-	blockNumberList := [...]*big.Int{big.NewInt(rpc.LatestBlockNumber.Int64()),
-		big.NewInt(rpc.FinalizedBlockNumber.Int64()), big.NewInt(rpc.SafeBlockNumber.Int64())}
 
 	for {
 		select {
 		case <-ticker.C:
 			// 开启一个协程获取，查找链上数据比较慢
 			go func() {
-				for _, blockNum := range blockNumberList {
-					getSpecialBlock(blockNum, client)
+				// 先获取finalized区块，新的head只需要检查之后的区块
+				finalized := getSpecialBlock(big.NewInt(rpc.FinalizedBlockNumber.Int64()), client)
+				_ = getSpecialBlock(big.NewInt(rpc.SafeBlockNumber.Int64()), client)
+				head := getSpecialBlock(big.NewInt(rpc.LatestBlockNumber.Int64()), client)
+				if finalized != nil && head != nil {
+					s.CheckReorg(client, head.Header(), finalized.NumberU64())
 				}
 			}()
 		}
@@ -50,8 +88,72 @@ func (s *EthService) GetBlock() {
 
 }
 
+// CheckReorg Walk back from a new head by parent hash and mark the cached blocks that are no longer on the chain as orphaned,
+// the canonical block of their number is fetched again. The walk ends at the last checked head if it is still an ancestor,
+// at the lowest cached block, or at the finalized block, which can not be reorged.
+func (s *EthService) CheckReorg(client *chainclient.Client, head *types.Header, finalized uint64) {
+	s.checkReorg(client, blockStore{}, head, finalized)
+}
+
+func (s *EthService) checkReorg(client reorgChain, store reorgStore, head *types.Header, finalized uint64) {
+	reorgMu.Lock()
+	defer reorgMu.Unlock()
+
+	// only the blocks after the finalized block and within MaxReorgDepth of the head can be reorged
+	from := finalized + 1
+	if head.Number.Uint64() > MaxReorgDepth && head.Number.Uint64()-MaxReorgDepth > from {
+		from = head.Number.Uint64() - MaxReorgDepth
+	}
+	cached, err := store.CanonicalBlocksFrom(from)
+	if err != nil {
+		log.Logger.Sugar().Error("CheckReorg CanonicalBlocksFrom err ", err)
+		return
+	}
+	stop := head.Number.Uint64()
+	for number := range cached {
+		if number < stop {
+			stop = number
+		}
+	}
+
+	cur := head
+	for {
+		number := cur.Number.Uint64()
+		if cur.Hash().String() == lastHead {
+			break
+		}
+		if block, ok := cached[number]; ok && block.Hash != cur.Hash().String() {
+			log.Logger.Sugar().Info("CheckReorg block reorged ", number, " ", block.Hash, " ", cur.Hash().String())
+			err = store.MarkOrphaned(block.Hash)
+			if err != nil {
+				log.Logger.Sugar().Error("CheckReorg MarkOrphaned err ", number, " ", err)
+				return
+			}
+			canonical, err := client.BlockByHash(context.Background(), cur.Hash())
+			if err != nil {
+				log.Logger.Sugar().Error("CheckReorg BlockByHash err ", number, " ", err)
+				return
+			}
+			err = store.Save(canonical)
+			if err != nil {
+				log.Logger.Sugar().Error("CheckReorg Save err ", number, " ", err)
+				return
+			}
+		}
+		if number <= stop {
+			break
+		}
+		cur, err = client.HeaderByHash(context.Background(), cur.ParentHash)
+		if err != nil {
+			log.Logger.Sugar().Error("CheckReorg HeaderByHash err ", number-1, " ", err)
+			return
+		}
+	}
+	lastHead = head.Hash().String()
+}
+
 // func GetSpecialBlockTask(headCh <-chan string, finalizedCh <-chan string, safeCh <-chan string) {
-// 	client, err := ethclient.Dial(config.Config.TestNet.TestEthUrl)
+// 	client, err := chainclient.GetStudyClient()
 // 	if err != nil {
 // 		log.Logger.Error(err.Error())
 // 		return
@@ -70,13 +172,14 @@ func (s *EthService) GetBlock() {
 // 	}
 // }
 
-func getSpecialBlock(blockNum *big.Int, client *ethclient.Client) {
+func getSpecialBlock(blockNum *big.Int, client *chainclient.Client) *types.Block {
 	block, err := client.BlockByNumber(context.Background(), blockNum)
 	if err != nil {
 		log.Logger.Error(err.Error())
-		return
+		return nil
 	}
 
 	blockResp := models.NewBlock(block)
 	db.RedisSet(common.SPECIAL_BLOCK_KEY_PREFIX+blockNum.String(), blockResp, 60)
+	return block
 }
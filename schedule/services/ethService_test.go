@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"pledge-backend/schedule/models"
+	"reflect"
+	"sort"
+	"testing"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeChain headers by hash
+type fakeChain struct {
+	headers map[ethCommon.Hash]*types.Header
+}
+
+func (c *fakeChain) HeaderByHash(ctx context.Context, hash ethCommon.Hash) (*types.Header, error) {
+	header, ok := c.headers[hash]
+	if !ok {
+		return nil, errors.New("header not found")
+	}
+	return header, nil
+}
+
+func (c *fakeChain) BlockByHash(ctx context.Context, hash ethCommon.Hash) (*types.Block, error) {
+	header, err := c.HeaderByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewBlockWithHeader(header), nil
+}
+
+// fakeStore cached blocks and the changes of a check
+type fakeStore struct {
+	cached    map[uint64]models.Block
+	orphaned  []uint64
+	saved     []uint64
+	orphanErr error
+}
+
+func (s *fakeStore) CanonicalBlocksFrom(from uint64) (map[uint64]models.Block, error) {
+	res := make(map[uint64]models.Block)
+	for number, block := range s.cached {
+		if number >= from {
+			res[number] = block
+		}
+	}
+	return res, nil
+}
+
+func (s *fakeStore) MarkOrphaned(hash string) error {
+	if s.orphanErr != nil {
+		return s.orphanErr
+	}
+	for number, block := range s.cached {
+		if block.Hash == hash {
+			s.orphaned = append(s.orphaned, number)
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) Save(block *types.Block) error {
+	s.saved = append(s.saved, block.NumberU64())
+	return nil
+}
+
+// buildChain headers from..to on top of parent, fork makes the hashes differ from another branch
+func buildChain(chain *fakeChain, parent ethCommon.Hash, from, to uint64, fork byte) []*types.Header {
+	headers := make([]*types.Header, 0, to-from+1)
+	for number := from; number <= to; number++ {
+		header := &types.Header{ParentHash: parent, Number: new(big.Int).SetUint64(number), Difficulty: big.NewInt(1), Extra: []byte{fork}}
+		chain.headers[header.Hash()] = header
+		headers = append(headers, header)
+		parent = header.Hash()
+	}
+	return headers
+}
+
+func cacheHeaders(cached map[uint64]models.Block, headers []*types.Header) {
+	for _, header := range headers {
+		cached[header.Number.Uint64()] = models.Block{Hash: header.Hash().String(), Number: header.Number.Uint64()}
+	}
+}
+
+func TestCheckReorg(t *testing.T) {
+	defer func() {
+		lastHead = ""
+	}()
+
+	tests := []struct {
+		name         string
+		setup        func(chain *fakeChain, store *fakeStore) (head *types.Header, finalized uint64)
+		wantOrphaned []uint64
+		wantSaved    []uint64
+		wantChecked  bool // the head is remembered as checked
+	}{
+		{
+			name: "no reorg",
+			setup: func(chain *fakeChain, store *fakeStore) (*types.Header, uint64) {
+				headers := buildChain(chain, ethCommon.Hash{}, 1, 20, 0)
+				cacheHeaders(store.cached, headers[9:])
+				return headers[19], 5
+			},
+			wantChecked: true,
+		},
+		{
+			name: "reorged blocks are orphaned and fetched again",
+			setup: func(chain *fakeChain, store *fakeStore) (*types.Header, uint64) {
+				headers := buildChain(chain, ethCommon.Hash{}, 1, 15, 0)
+				cacheHeaders(store.cached, headers[9:])
+				fork := buildChain(chain, headers[12].Hash(), 14, 16, 1)
+				return fork[2], 5
+			},
+			wantOrphaned: []uint64{14, 15},
+			wantSaved:    []uint64{14, 15},
+			wantChecked:  true,
+		},
+		{
+			name: "finalized blocks are not checked",
+			setup: func(chain *fakeChain, store *fakeStore) (*types.Header, uint64) {
+				headers := buildChain(chain, ethCommon.Hash{}, 1, 15, 0)
+				cacheHeaders(store.cached, headers[9:])
+				fork := buildChain(chain, headers[10].Hash(), 12, 15, 1)
+				return fork[3], 13
+			},
+			wantOrphaned: []uint64{14, 15},
+			wantSaved:    []uint64{14, 15},
+			wantChecked:  true,
+		},
+		{
+			name: "the walk ends at the last checked head",
+			setup: func(chain *fakeChain, store *fakeStore) (*types.Header, uint64) {
+				headers := buildChain(chain, ethCommon.Hash{}, 1, 20, 0)
+				cacheHeaders(store.cached, headers[9:])
+				// a stale cache entry below the checked head is not visited again
+				store.cached[12] = models.Block{Hash: "0x12", Number: 12}
+				lastHead = headers[14].Hash().String()
+				return headers[19], 5
+			},
+			wantChecked: true,
+		},
+		{
+			name: "the walk ends at the lowest cached block",
+			setup: func(chain *fakeChain, store *fakeStore) (*types.Header, uint64) {
+				headers := buildChain(chain, ethCommon.Hash{}, 1, 20, 0)
+				cacheHeaders(store.cached, headers[14:])
+				// headers below the cache are not fetched
+				for _, header := range headers[:14] {
+					delete(chain.headers, header.Hash())
+				}
+				return headers[19], 5
+			},
+			wantChecked: true,
+		},
+		{
+			name: "the walk ends at the max reorg depth",
+			setup: func(chain *fakeChain, store *fakeStore) (*types.Header, uint64) {
+				headers := buildChain(chain, ethCommon.Hash{}, 1, MaxReorgDepth+50, 0)
+				cacheHeaders(store.cached, headers[len(headers)-5:])
+				store.cached[10] = models.Block{Hash: "0x10", Number: 10}
+				return headers[len(headers)-1], 0
+			},
+			wantChecked: true,
+		},
+		{
+			name: "a failed write keeps the head unchecked",
+			setup: func(chain *fakeChain, store *fakeStore) (*types.Header, uint64) {
+				headers := buildChain(chain, ethCommon.Hash{}, 1, 15, 0)
+				cacheHeaders(store.cached, headers[9:])
+				fork := buildChain(chain, headers[13].Hash(), 15, 15, 1)
+				store.orphanErr = errors.New("db down")
+				return fork[0], 5
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastHead = ""
+			chain := &fakeChain{headers: make(map[ethCommon.Hash]*types.Header)}
+			store := &fakeStore{cached: make(map[uint64]models.Block)}
+			head, finalized := tt.setup(chain, store)
+
+			NewEthService().checkReorg(chain, store, head, finalized)
+
+			sort.Slice(store.orphaned, func(i, j int) bool { return store.orphaned[i] < store.orphaned[j] })
+			sort.Slice(store.saved, func(i, j int) bool { return store.saved[i] < store.saved[j] })
+			if !reflect.DeepEqual(store.orphaned, tt.wantOrphaned) {
+				t.Errorf("orphaned = %v, want %v", store.orphaned, tt.wantOrphaned)
+			}
+			if !reflect.DeepEqual(store.saved, tt.wantSaved) {
+				t.Errorf("saved = %v, want %v", store.saved, tt.wantSaved)
+			}
+			if checked := lastHead == head.Hash().String(); checked != tt.wantChecked {
+				t.Errorf("head checked = %v, want %v", checked, tt.wantChecked)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"pledge-backend/chainclient"
+	"pledge-backend/config"
+	"pledge-backend/contract/bindings"
+	"pledge-backend/log"
+	"pledge-backend/pubsub"
+	"pledge-backend/schedule/models"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type EventIndexer struct{}
+
+// indexerMu a run can take longer than the job interval, the next run is skipped until it ended
+var indexerMu sync.Mutex
+
+func NewEventIndexer() *EventIndexer {
+	return &EventIndexer{}
+}
+
+// IndexAllPoolEvents index pledge pool events on every network
+func (s *EventIndexer) IndexAllPoolEvents() {
+	if !indexerMu.TryLock() {
+		return
+	}
+	defer indexerMu.Unlock()
+
+	for _, chain := range config.EnabledChains() {
+		s.IndexPoolEvents(chain.PledgePoolToken, chain.ChainId, chain.DeployBlock)
+	}
+}
+
+// IndexPoolEvents walk the block ranges after the saved cursor, or from the deployment block, up to the confirmed head
+func (s *EventIndexer) IndexPoolEvents(contractAddress, chainId string, deployBlock uint64) {
+
+	log.Logger.Sugar().Info("IndexPoolEvents ", contractAddress+" "+chainId)
+	ethereumConn, err := chainclient.GetClient(chainId)
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return
+	}
+
+	head, err := ethereumConn.BlockNumber(context.Background())
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return
+	}
+	if head <= config.Config.Indexer.Confirmations {
+		return
+	}
+	confirmedHead := head - config.Config.Indexer.Confirmations
+
+	lastBlock, hasCursor, err := models.NewEventCursor().GetLastBlock(chainId, contractAddress)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return
+	}
+	from := deployBlock
+	if hasCursor {
+		from = lastBlock + 1
+	}
+
+	batchSize := config.Config.Indexer.BatchSize
+	if batchSize == 0 {
+		batchSize = 2000
+	}
+
+	for from <= confirmedHead {
+		to := from + batchSize - 1
+		if to > confirmedHead {
+			to = confirmedHead
+		}
+
+		events, err := s.FilterPoolEvents(ethereumConn, chainId, contractAddress, from, to)
+		if err != nil {
+			log.Logger.Sugar().Error("IndexPoolEvents FilterPoolEvents err ", chainId, " ", from, "-", to, " ", err)
+			return
+		}
+
+		err = models.NewPoolEvent().SaveEvents(chainId, contractAddress, events, to)
+		if err != nil {
+			log.Logger.Sugar().Error("IndexPoolEvents SaveEvents err ", chainId, " ", from, "-", to, " ", err)
+			return
+		}
+		log.Logger.Sugar().Info("IndexPoolEvents ", chainId, " ", from, "-", to, " events ", len(events))
+		s.PublishPositions(chainId, events)
+
+		from = to + 1
+	}
+}
+
+// PublishPositions tell the subscribers of a wallet about its new events, one message per wallet and batch
+func (s *EventIndexer) PublishPositions(chainId string, events []models.PoolEvent) {
+	accountEvents := make(map[string][]models.PoolEvent)
+	for _, event := range events {
+		if event.Account == "" {
+			continue
+		}
+		accountEvents[event.Account] = append(accountEvents[event.Account], event)
+	}
+	for account, accountEvent := range accountEvents {
+		err := pubsub.Publish(pubsub.PositionTopic(chainId, account), accountEvent)
+		if err != nil {
+			log.Logger.Sugar().Error("PublishPositions err ", chainId, " ", account, " ", err)
+			return
+		}
+	}
+}
+
+// poolEventDecoder fill a PoolEvent from the raw log of one pledge pool event
+type poolEventDecoder func(contract *bind.BoundContract, name string, raw types.Log, event *models.PoolEvent) error
+
+// decodePoolEvent unpack the raw log into the binding struct T, then copy its fields to the PoolEvent
+func decodePoolEvent[T any](fill func(e *T, event *models.PoolEvent)) poolEventDecoder {
+	return func(contract *bind.BoundContract, name string, raw types.Log, event *models.PoolEvent) error {
+		e := new(T)
+		err := contract.UnpackLog(e, name, raw)
+		if err != nil {
+			return err
+		}
+		fill(e, event)
+		return nil
+	}
+}
+
+// poolEventDecoders the indexed pledge pool events by name
+var poolEventDecoders = map[string]poolEventDecoder{
+	"DepositLend": decodePoolEvent(func(e *bindings.PledgePoolTokenDepositLend, event *models.PoolEvent) {
+		event.Account, event.Token = e.From.String(), e.Token.String()
+		event.Amount, event.ExtraAmount = models.BigIntString(e.Amount), models.BigIntString(e.MintAmount)
+	}),
+	"DepositBorrow": decodePoolEvent(func(e *bindings.PledgePoolTokenDepositBorrow, event *models.PoolEvent) {
+		event.Account, event.Token = e.From.String(), e.Token.String()
+		event.Amount, event.ExtraAmount = models.BigIntString(e.Amount), models.BigIntString(e.MintAmount)
+	}),
+	"WithdrawLend": decodePoolEvent(func(e *bindings.PledgePoolTokenWithdrawLend, event *models.PoolEvent) {
+		event.Account, event.Token = e.From.String(), e.Token.String()
+		event.Amount, event.ExtraAmount = models.BigIntString(e.Amount), models.BigIntString(e.BurnAmount)
+	}),
+	"WithdrawBorrow": decodePoolEvent(func(e *bindings.PledgePoolTokenWithdrawBorrow, event *models.PoolEvent) {
+		event.Account, event.Token = e.From.String(), e.Token.String()
+		event.Amount, event.ExtraAmount = models.BigIntString(e.Amount), models.BigIntString(e.BurnAmount)
+	}),
+	"ClaimLend": decodePoolEvent(func(e *bindings.PledgePoolTokenClaimLend, event *models.PoolEvent) {
+		event.Account, event.Token, event.Amount = e.From.String(), e.Token.String(), models.BigIntString(e.Amount)
+	}),
+	"ClaimBorrow": decodePoolEvent(func(e *bindings.PledgePoolTokenClaimBorrow, event *models.PoolEvent) {
+		event.Account, event.Token, event.Amount = e.From.String(), e.Token.String(), models.BigIntString(e.Amount)
+	}),
+	"RefundLend": decodePoolEvent(func(e *bindings.PledgePoolTokenRefundLend, event *models.PoolEvent) {
+		event.Account, event.Token, event.Amount = e.From.String(), e.Token.String(), models.BigIntString(e.Refund)
+	}),
+	"RefundBorrow": decodePoolEvent(func(e *bindings.PledgePoolTokenRefundBorrow, event *models.PoolEvent) {
+		event.Account, event.Token, event.Amount = e.From.String(), e.Token.String(), models.BigIntString(e.Refund)
+	}),
+	"EmergencyLendWithdrawal": decodePoolEvent(func(e *bindings.PledgePoolTokenEmergencyLendWithdrawal, event *models.PoolEvent) {
+		event.Account, event.Token, event.Amount = e.From.String(), e.Token.String(), models.BigIntString(e.Amount)
+	}),
+	"EmergencyBorrowWithdrawal": decodePoolEvent(func(e *bindings.PledgePoolTokenEmergencyBorrowWithdrawal, event *models.PoolEvent) {
+		event.Account, event.Token, event.Amount = e.From.String(), e.Token.String(), models.BigIntString(e.Amount)
+	}),
+	"Redeem": decodePoolEvent(func(e *bindings.PledgePoolTokenRedeem, event *models.PoolEvent) {
+		event.Account, event.Token, event.Amount = e.Recieptor.String(), e.Token.String(), models.BigIntString(e.Amount)
+	}),
+	"Swap": decodePoolEvent(func(e *bindings.PledgePoolTokenSwap, event *models.PoolEvent) {
+		event.Token, event.ToToken = e.FromCoin.String(), e.ToCoin.String()
+		event.Amount, event.ExtraAmount = models.BigIntString(e.FromValue), models.BigIntString(e.ToValue)
+	}),
+	"StateChange": decodePoolEvent(func(e *bindings.PledgePoolTokenStateChange, event *models.PoolEvent) {
+		event.PoolId = int(e.Pid.Int64()) + 1 // pool_id in poolbases starts from 1
+		event.BeforeState, event.AfterState = models.BigIntString(e.BeforeState), models.BigIntString(e.AfterState)
+	}),
+}
+
+// FilterPoolEvents decode every pledge pool event in [from, to], one log query for all the indexed events
+func (s *EventIndexer) FilterPoolEvents(client bind.ContractFilterer, chainId, contractAddress string, from, to uint64) ([]models.PoolEvent, error) {
+	poolAbi, err := bindings.PledgePoolTokenMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	contract := bind.NewBoundContract(common.HexToAddress(contractAddress), *poolAbi, nil, nil, client)
+	eventNames := make(map[common.Hash]string, len(poolEventDecoders))
+	topics := make([]common.Hash, 0, len(poolEventDecoders))
+	for name := range poolEventDecoders {
+		id := poolAbi.Events[name].ID
+		eventNames[id] = name
+		topics = append(topics, id)
+	}
+
+	logs, err := client.FilterLogs(context.Background(), ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{common.HexToAddress(contractAddress)},
+		Topics:    [][]common.Hash{topics},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]models.PoolEvent, 0, len(logs))
+	for _, raw := range logs {
+		if raw.Removed || len(raw.Topics) == 0 {
+			continue
+		}
+		name, ok := eventNames[raw.Topics[0]]
+		if !ok {
+			continue
+		}
+		event := models.NewPoolEventFromLog(chainId, contractAddress, name, raw)
+		err = poolEventDecoders[name](contract, name, raw, &event)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
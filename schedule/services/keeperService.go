@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"pledge-backend/chainclient"
+	"pledge-backend/config"
+	"pledge-backend/contract/bindings"
+	"pledge-backend/log"
+	serviceCommon "pledge-backend/schedule/common"
+	"pledge-backend/schedule/models"
+	"pledge-backend/utils"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	KeeperActionSettle    = "settle"
+	KeeperActionFinish    = "finish"
+	KeeperActionLiquidate = "liquidate"
+)
+
+type Keeper struct{}
+
+func NewKeeper() *Keeper {
+	return &Keeper{}
+}
+
+// keeperTarget the chain a keeper run works on
+type keeperTarget struct {
+	chainId     string
+	conn        *chainclient.Client
+	pledgePool  *bindings.PledgePoolToken
+	transactor  *bind.TransactOpts
+	sendTimeout time.Duration
+	waitTimeout time.Duration
+}
+
+// Run Drive settle, finish and liquidate of every pool
+func (s *Keeper) Run() {
+	if !config.Config.Keeper.Enabled {
+		return
+	}
+
+	for _, chain := range config.EnabledChains() {
+		s.RunPools(chain.PledgePoolToken, chain.ChainId)
+	}
+}
+
+// RunPools Evaluate the checkout predicates of the pools that can still change state and send the matching transaction
+func (s *Keeper) RunPools(contractAddress, chainId string) {
+
+	ethereumConn, err := chainclient.GetClient(chainId)
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return
+	}
+
+	pledgePoolToken, err := bindings.NewPledgePoolToken(common.HexToAddress(contractAddress), ethereumConn)
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return
+	}
+
+	privateKeyEcdsa, err := crypto.HexToECDSA(serviceCommon.KeeperPrivateKey)
+	if err != nil {
+		log.Logger.Sugar().Error("Keeper private key err ", err)
+		return
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKeyEcdsa, big.NewInt(utils.StringToInt64(chainId)))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return
+	}
+
+	target := &keeperTarget{
+		chainId:     chainId,
+		conn:        ethereumConn,
+		pledgePool:  pledgePoolToken,
+		transactor:  auth,
+		sendTimeout: time.Duration(config.Config.Keeper.SendTimeout) * time.Second,
+		waitTimeout: time.Duration(config.Config.Keeper.ReceiptTimeout) * time.Second,
+	}
+
+	for _, state := range []string{serviceCommon.PoolStateMatch, serviceCommon.PoolStateExecution} {
+		pools, err := models.NewPoolBase().GetPoolsByState(chainId, state)
+		if err != nil {
+			log.Logger.Error(err.Error())
+			return
+		}
+		for _, p := range pools {
+			s.CheckPool(target, p.PoolId)
+		}
+	}
+}
+
+// CheckPool Read the current state on chain and run the transition whose checkout predicate is true
+func (s *Keeper) CheckPool(target *keeperTarget, poolId int) {
+	pid := big.NewInt(int64(poolId - 1))
+
+	state, err := target.pledgePool.GetPoolState(nil, pid)
+	if err != nil {
+		log.Logger.Sugar().Error("Keeper GetPoolState err ", target.chainId, " ", poolId, " ", err)
+		return
+	}
+	fromState := state.String()
+
+	switch fromState {
+	case serviceCommon.PoolStateMatch:
+		ok, err := target.pledgePool.CheckoutSettle(nil, pid)
+		if err != nil {
+			log.Logger.Sugar().Error("Keeper CheckoutSettle err ", target.chainId, " ", poolId, " ", err)
+			return
+		}
+		if ok {
+			s.Submit(target, poolId, KeeperActionSettle, fromState, target.pledgePool.Settle)
+		}
+	case serviceCommon.PoolStateExecution:
+		// liquidation protects lenders, so it goes before finish
+		ok, err := target.pledgePool.CheckoutLiquidate(nil, pid)
+		if err != nil {
+			log.Logger.Sugar().Error("Keeper CheckoutLiquidate err ", target.chainId, " ", poolId, " ", err)
+			return
+		}
+		if ok {
+			s.Submit(target, poolId, KeeperActionLiquidate, fromState, target.pledgePool.Liquidate)
+			return
+		}
+		ok, err = target.pledgePool.CheckoutFinish(nil, pid)
+		if err != nil {
+			log.Logger.Sugar().Error("Keeper CheckoutFinish err ", target.chainId, " ", poolId, " ", err)
+			return
+		}
+		if ok {
+			s.Submit(target, poolId, KeeperActionFinish, fromState, target.pledgePool.Finish)
+		}
+	}
+}
+
+// Submit Reserve the transition, send the transaction and wait for its receipt
+func (s *Keeper) Submit(target *keeperTarget, poolId int, action, fromState string, send func(opts *bind.TransactOpts, _pid *big.Int) (*types.Transaction, error)) {
+	keeperAction, claimed, err := models.NewKeeperAction().ClaimAction(target.chainId, poolId, action, fromState, config.Config.Keeper.MaxAttempts, config.Config.Keeper.RetryInterval)
+	if err != nil {
+		log.Logger.Sugar().Error("Keeper ClaimAction err ", target.chainId, " ", poolId, " ", action, " ", err)
+		return
+	}
+	if !claimed {
+		// a transaction from an earlier tick may have been mined since
+		if keeperAction.Status == models.KeeperStatusPending {
+			s.Reconcile(target, keeperAction)
+		}
+		return
+	}
+
+	log.Logger.Sugar().Info("Keeper submit ", target.chainId, " ", poolId, " ", action, " attempt ", keeperAction.Attempts)
+
+	sendCtx, cancel := context.WithTimeout(context.Background(), target.sendTimeout)
+	defer cancel()
+	transactOpts := bind.TransactOpts{
+		From:    target.transactor.From,
+		Signer:  target.transactor.Signer,
+		Value:   big.NewInt(0),
+		Context: sendCtx,
+	}
+	tx, err := send(&transactOpts, big.NewInt(int64(poolId-1)))
+	if err != nil {
+		log.Logger.Sugar().Error("Keeper send err ", target.chainId, " ", poolId, " ", action, " ", err)
+		s.FailAction(keeperAction, err.Error())
+		return
+	}
+	err = models.NewKeeperAction().SaveTxHash(keeperAction.Id, tx.Hash().String())
+	if err != nil {
+		// the row stays pending without a hash, the receipt below still finishes it
+		log.Logger.Sugar().Error("Keeper SaveTxHash err ", target.chainId, " ", poolId, " ", action, " ", tx.Hash().String(), " ", err)
+	}
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), target.waitTimeout)
+	defer waitCancel()
+	receipt, err := bind.WaitMined(waitCtx, target.conn, tx)
+	if err != nil {
+		// keep it pending, the next tick reconciles it by hash instead of sending again
+		log.Logger.Sugar().Error("Keeper WaitMined err ", target.chainId, " ", poolId, " ", action, " ", tx.Hash().String(), " ", err)
+		return
+	}
+	s.FinishReceipt(keeperAction, receipt)
+}
+
+// Reconcile Finish a pending action whose receipt is available now, or fail it when its transaction is lost
+// so the transition is claimed again
+func (s *Keeper) Reconcile(target *keeperTarget, keeperAction *models.KeeperAction) {
+	if keeperAction.TxHash == "" {
+		// the send or the hash write did not finish, give the tick that claimed it time to end
+		updatedAt := utils.StringToInt64(utils.GetTimeStampByFormat(keeperAction.UpdatedAt))
+		if time.Since(time.Unix(updatedAt, 0)) < target.sendTimeout+target.waitTimeout {
+			return
+		}
+		log.Logger.Sugar().Info("Keeper pending action without transaction ", keeperAction.Id)
+		s.FailAction(keeperAction, "no transaction hash saved")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), target.sendTimeout)
+	defer cancel()
+	txHash := common.HexToHash(keeperAction.TxHash)
+	receipt, err := target.conn.TransactionReceipt(ctx, txHash)
+	isPending := false
+	if errors.Is(err, ethereum.NotFound) {
+		var txErr error
+		_, isPending, txErr = target.conn.TransactionByHash(ctx, txHash)
+		if txErr != nil && !errors.Is(txErr, ethereum.NotFound) {
+			err = txErr
+		}
+	}
+	if errors.Is(err, ethereum.NotFound) && !isPending {
+		// dropped by the node, the checkout predicate is evaluated again
+		log.Logger.Sugar().Info("Keeper dropped action ", keeperAction.Id, " ", keeperAction.TxHash)
+		s.FailAction(keeperAction, "transaction not found")
+		return
+	}
+	if err != nil {
+		log.Logger.Sugar().Info("Keeper pending action ", keeperAction.Id, " ", keeperAction.TxHash, " ", err)
+		return
+	}
+	s.FinishReceipt(keeperAction, receipt)
+}
+
+// FinishReceipt Save the outcome of a mined transaction
+func (s *Keeper) FinishReceipt(keeperAction *models.KeeperAction, receipt *types.Receipt) {
+	log.Logger.Sugar().Info("Keeper action ", keeperAction.Id, " ", receipt.TxHash.String(), " ", receipt.Status)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		s.FailAction(keeperAction, "transaction reverted")
+		return
+	}
+	err := models.NewKeeperAction().FinishAction(keeperAction.Id, models.KeeperStatusSuccess, "")
+	if err != nil {
+		log.Logger.Error(err.Error())
+	}
+}
+
+// FailAction Save a failed attempt, after the last one the transition is not claimed again and an email is sent
+func (s *Keeper) FailAction(keeperAction *models.KeeperAction, errMsg string) {
+	err := models.NewKeeperAction().FinishAction(keeperAction.Id, models.KeeperStatusFailed, errMsg)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return
+	}
+	if keeperAction.Attempts < keeperAction.MaxAttempts {
+		return
+	}
+	err = utils.SendEmail(s.EmailBody(keeperAction, errMsg), 2)
+	if err != nil {
+		log.Logger.Error(err.Error())
+	}
+}
+
+// EmailBody email body
+func (s *Keeper) EmailBody(keeperAction *models.KeeperAction, errMsg string) []byte {
+	log.Logger.Sugar().Info("keeper gave up ", keeperAction.ChainId, " ", keeperAction.PoolId, " ", keeperAction.Action, " ", errMsg)
+	body := fmt.Sprintf(`<p>&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;The keeper gave up the <strong>%s</strong> of pool <strong><span style="color: rgb(255, 0, 0);"> %d </span></strong> on chain %s after %d attempts, the last one failed with: %s
+</p>`, keeperAction.Action, keeperAction.PoolId, keeperAction.ChainId, keeperAction.Attempts, errMsg)
+	return []byte(body)
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"fmt"
+	"math/big"
+	"pledge-backend/chainclient"
+	"pledge-backend/config"
+	"pledge-backend/contract/bindings"
+	"pledge-backend/db"
+	"pledge-backend/log"
+	serviceCommon "pledge-backend/schedule/common"
+	"pledge-backend/schedule/models"
+	"pledge-backend/utils"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+type LiquidationMonitor struct{}
+
+func NewLiquidationMonitor() *LiquidationMonitor {
+	return &LiquidationMonitor{}
+}
+
+// Monitor Check the collateral health of pools in execution state
+func (s *LiquidationMonitor) Monitor() {
+
+	for _, chain := range config.EnabledChains() {
+		s.MonitorPools(chain.PledgePoolToken, chain.ChainId)
+	}
+}
+
+// MonitorPools Save the health of every executing pool and send an email when it crosses a warning band
+func (s *LiquidationMonitor) MonitorPools(contractAddress, chainId string) {
+
+	pools, err := models.NewPoolBase().GetPoolsByState(chainId, serviceCommon.PoolStateExecution)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return
+	}
+	if len(pools) == 0 {
+		return
+	}
+
+	ethereumConn, err := chainclient.GetClient(chainId)
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return
+	}
+
+	pledgePoolToken, err := bindings.NewPledgePoolTokenCaller(common.HexToAddress(contractAddress), ethereumConn)
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return
+	}
+
+	bands := s.WarningBands()
+
+	for _, p := range pools {
+		poolId := utils.IntToString(p.PoolId)
+
+		poolData, err := models.NewPoolData().GetPoolData(chainId, poolId)
+		if err != nil {
+			log.Logger.Sugar().Error("MonitorPools GetPoolData err ", chainId, " ", poolId, " ", err)
+			continue
+		}
+
+		prices, err := pledgePoolToken.GetUnderlyingPriceView(nil, big.NewInt(int64(p.PoolId-1)))
+		if err != nil {
+			log.Logger.Sugar().Error("MonitorPools GetUnderlyingPriceView err ", chainId, " ", poolId, " ", err)
+			continue
+		}
+
+		poolHealth, err := s.PoolHealth(&p, &poolData, prices)
+		if err != nil {
+			log.Logger.Sugar().Error("MonitorPools PoolHealth err ", chainId, " ", poolId, " ", err)
+			continue
+		}
+		health, _ := decimal.NewFromString(poolHealth.Health)
+		poolHealth.Band = s.CrossedBands(health, bands)
+
+		err = models.NewPoolHealth().SavePoolHealth(poolHealth)
+		if err != nil {
+			log.Logger.Sugar().Error("MonitorPools SavePoolHealth err ", chainId, " ", poolId, " ", err)
+		}
+
+		// only alert when the pool gets worse than the last notified band
+		redisKey := "pool_health_band:" + chainId + "_" + poolId
+		lastBand, err := db.RedisGetInt64(redisKey)
+		if err != nil {
+			lastBand = 0
+		}
+		if int64(poolHealth.Band) > lastBand {
+			emailBody := s.EmailBody(chainId, poolHealth, bands[poolHealth.Band-1])
+			err = utils.SendEmail(emailBody, 2)
+			if err != nil {
+				log.Logger.Error(err.Error())
+			}
+		}
+		err = db.RedisSetInt64(redisKey, int64(poolHealth.Band), 0)
+		if err != nil {
+			log.Logger.Sugar().Error("MonitorPools RedisSetInt64 err ", redisKey, " ", err)
+		}
+	}
+}
+
+// PoolHealth compute the collateral ratio the same way as checkoutLiquidate does
+func (s *LiquidationMonitor) PoolHealth(poolBase *models.PoolBase, poolData *models.PoolData, prices [2]*big.Int) (*models.PoolHealth, error) {
+	lendPrice := decimal.NewFromBigInt(prices[0], 0)
+	borrowPrice := decimal.NewFromBigInt(prices[1], 0)
+	if lendPrice.IsZero() {
+		return nil, fmt.Errorf("lend token price is zero")
+	}
+
+	settleAmountLend, err := decimal.NewFromString(poolData.SettleAmountLend)
+	if err != nil {
+		return nil, err
+	}
+	if settleAmountLend.IsZero() {
+		return nil, fmt.Errorf("settle amount lend is zero")
+	}
+	settleAmountBorrow, err := decimal.NewFromString(poolData.SettleAmountBorrow)
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := decimal.NewFromString(poolBase.AutoLiquidateThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	borrowValue := settleAmountBorrow.Mul(borrowPrice).Div(lendPrice)
+	collateralRatio := borrowValue.Div(settleAmountLend)
+	liquidateRatio := decimal.NewFromInt(1).Add(threshold.Div(serviceCommon.RateDecimal))
+	health := collateralRatio.Div(liquidateRatio)
+
+	return &models.PoolHealth{
+		ChainId:            poolBase.ChainId,
+		PoolId:             poolBase.PoolId,
+		LendPrice:          lendPrice.String(),
+		BorrowPrice:        borrowPrice.String(),
+		SettleAmountLend:   poolData.SettleAmountLend,
+		SettleAmountBorrow: poolData.SettleAmountBorrow,
+		MartgageRate:       poolBase.MartgageRate,
+		LiquidateRatio:     liquidateRatio.String(),
+		CollateralRatio:    collateralRatio.StringFixed(8),
+		Health:             health.StringFixed(8),
+	}, nil
+}
+
+// WarningBands configured warning bands from high to low, invalid values are skipped
+func (s *LiquidationMonitor) WarningBands() []decimal.Decimal {
+	bands := make([]decimal.Decimal, 0, len(config.Config.Risk.WarningBands))
+	for _, b := range config.Config.Risk.WarningBands {
+		band, err := decimal.NewFromString(b)
+		if err != nil {
+			log.Logger.Sugar().Error("WarningBands invalid band ", b)
+			continue
+		}
+		bands = append(bands, band)
+	}
+	sort.Slice(bands, func(i, j int) bool {
+		return bands[i].GreaterThan(bands[j])
+	})
+	return bands
+}
+
+// CrossedBands number of warning bands above the health value, bands are sorted from high to low
+func (s *LiquidationMonitor) CrossedBands(health decimal.Decimal, bands []decimal.Decimal) int {
+	crossed := 0
+	for _, band := range bands {
+		if health.LessThan(band) {
+			crossed++
+		}
+	}
+	return crossed
+}
+
+// EmailBody email body
+func (s *LiquidationMonitor) EmailBody(chainId string, poolHealth *models.PoolHealth, band decimal.Decimal) []byte {
+	log.Logger.Sugar().Info("pool health warning ", chainId, " ", poolHealth.PoolId, " ", poolHealth.Health, " ", band.String())
+	body := fmt.Sprintf(`<p>&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;&nbsp;The health of pool <strong><span style="color: rgb(255, 0, 0);"> %d </span></strong> on chain %s is <strong>%s</strong>, below the warning band %s. The collateral ratio is %s and the pool is liquidated below %s.
+</p>`, poolHealth.PoolId, chainId, poolHealth.Health, band.String(), poolHealth.CollateralRatio, poolHealth.LiquidateRatio)
+	return []byte(body)
+}
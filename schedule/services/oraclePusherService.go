@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"pledge-backend/chainclient"
+	"pledge-backend/config"
+	"pledge-backend/contract/bindings"
+	"pledge-backend/log"
+	serviceCommon "pledge-backend/schedule/common"
+	"pledge-backend/schedule/models"
+	"pledge-backend/utils"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+type OraclePusher struct{}
+
+// pusherMu a run waiting on receipts can take longer than the job interval, an overlapping run would see
+// the same deviation and push it again, so the next run is skipped until it ended
+var pusherMu sync.Mutex
+
+func NewOraclePusher() *OraclePusher {
+	return &OraclePusher{}
+}
+
+// oracleTarget the oracle of a chain a push run works on
+type oracleTarget struct {
+	chain          *config.ChainConfig
+	conn           *chainclient.Client
+	oracle         *bindings.BscPledgeOracleMainnetToken
+	sendTimeout    time.Duration
+	receiptTimeout time.Duration
+}
+
+// Run Push the off-chain prices that moved or whose heartbeat elapsed to the oracle of every chain that pushes prices
+func (s *OraclePusher) Run() {
+	if !pusherMu.TryLock() {
+		return
+	}
+	defer pusherMu.Unlock()
+
+	sources := make([]PriceSource, 0)
+	for _, source := range NewPriceSources() {
+		// the oracle is what is being updated, it can not vote on its own price
+		if source.Name() != "oracle" {
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) == 0 {
+		log.Logger.Error("OraclePusher no off-chain price source")
+		return
+	}
+
+	for _, chain := range config.EnabledChains() {
+		assets := s.PushAssets(&chain)
+		if len(assets) == 0 {
+			continue
+		}
+		s.PushChain(&chain, sources, assets)
+	}
+}
+
+// PushAssets Tokens of a chain whose price is pushed, the plgr token if plgr_price_push is set and the oracle_push_tokens
+func (s *OraclePusher) PushAssets(chain *config.ChainConfig) []string {
+	assets := make([]string, 0, len(chain.OraclePushTokens)+1)
+	if chain.PlgrPricePush {
+		assets = append(assets, chain.PlgrAddress)
+	}
+	for _, token := range chain.OraclePushTokens {
+		if !utils.IsContain(token, assets) {
+			assets = append(assets, token)
+		}
+	}
+	return assets
+}
+
+// PushChain Compare the off-chain and oracle price of the assets and send the ones that need a push in one transaction
+func (s *OraclePusher) PushChain(chain *config.ChainConfig, sources []PriceSource, assets []string) {
+	ethereumConn, err := chainclient.GetClient(chain.ChainId)
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return
+	}
+	oracle, err := bindings.NewBscPledgeOracleMainnetToken(common.HexToAddress(chain.OracleToken), ethereumConn)
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return
+	}
+	target := &oracleTarget{
+		chain:          chain,
+		conn:           ethereumConn,
+		oracle:         oracle,
+		sendTimeout:    time.Duration(config.Config.OraclePusher.SendTimeout) * time.Second,
+		receiptTimeout: time.Duration(config.Config.OraclePusher.ReceiptTimeout) * time.Second,
+	}
+
+	// a transaction of an earlier run is finished first so the same prices are not sent twice
+	if !s.Reconcile(target) {
+		return
+	}
+
+	oraclePrices := &OraclePriceSource{}
+	oraclePrices.Prefetch(chain, assets)
+	updates := make([]models.OracleUpdate, 0, len(assets))
+	for _, asset := range assets {
+		update, err := s.CheckAsset(target, sources, oraclePrices, asset)
+		if err != nil {
+			log.Logger.Sugar().Error("OraclePusher CheckAsset err ", chain.ChainId, " ", asset, " ", err)
+			continue
+		}
+		if update != nil {
+			updates = append(updates, *update)
+		}
+	}
+	if len(updates) == 0 {
+		return
+	}
+	s.Push(target, updates)
+}
+
+// CheckAsset The update of an asset whose off-chain price deviates beyond the threshold from the oracle
+// or whose last push is older than the heartbeat, nil if it does not need a push
+func (s *OraclePusher) CheckAsset(target *oracleTarget, sources []PriceSource, oraclePrices *OraclePriceSource, asset string) (*models.OracleUpdate, error) {
+	token := &models.TokenInfo{Token: asset, ChainId: target.chain.ChainId}
+	price, err := NewTokenPrice().AggregatePrice(sources, target.chain, token)
+	if err != nil {
+		return nil, err
+	}
+	oldPrice, err := oraclePrices.Price(target.chain, token)
+	if err != nil {
+		return nil, err
+	}
+
+	newPrice := decimal.NewFromInt(price)
+	update := &models.OracleUpdate{
+		ChainId:  target.chain.ChainId,
+		Asset:    asset,
+		OldPrice: oldPrice.String(),
+		NewPrice: newPrice.String(),
+	}
+	if oldPrice.IsZero() {
+		update.Reason = models.OracleReasonDeviation
+		return update, nil
+	}
+
+	deviation := newPrice.Sub(oldPrice).Abs().Div(oldPrice).Mul(decimal.NewFromInt(100))
+	update.Deviation = deviation.StringFixed(4)
+	if deviation.GreaterThan(decimal.NewFromFloat(config.Config.OraclePusher.Deviation)) {
+		update.Reason = models.OracleReasonDeviation
+		return update, nil
+	}
+
+	lastPush, err := models.NewOracleUpdate().LastSuccess(target.chain.ChainId, asset)
+	if err != nil {
+		return nil, err
+	}
+	if lastPush == nil || time.Now().Unix()-lastPush.PushedAt >= config.Config.OraclePusher.Heartbeat {
+		update.Reason = models.OracleReasonHeartbeat
+		return update, nil
+	}
+	return nil, nil
+}
+
+// Push Send the prices with SetPrice, or SetPrices for several assets, wait for the receipt and check the oracle
+func (s *OraclePusher) Push(target *oracleTarget, updates []models.OracleUpdate) {
+	chainId := target.chain.ChainId
+	privateKeyEcdsa, err := crypto.HexToECDSA(serviceCommon.PlgrAdminPrivateKey)
+	if err != nil {
+		log.Logger.Sugar().Error("OraclePusher private key err ", err)
+		return
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKeyEcdsa, big.NewInt(utils.StringToInt64(chainId)))
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(context.Background(), target.sendTimeout)
+	defer cancel()
+	transactOpts := bind.TransactOpts{
+		From:    auth.From,
+		Signer:  auth.Signer,
+		Value:   big.NewInt(0),
+		Context: sendCtx,
+	}
+
+	// the rows are saved first, a transaction is never sent without them
+	pushedAt := time.Now().Unix()
+	for i := range updates {
+		updates[i].PushedAt = pushedAt
+		updates[i].Status = models.OracleUpdatePending
+	}
+	err = models.NewOracleUpdate().CreateUpdates(updates)
+	if err != nil {
+		log.Logger.Sugar().Error("OraclePusher CreateUpdates err ", chainId, " ", err)
+		return
+	}
+	ids := make([]int, 0, len(updates))
+	for _, update := range updates {
+		ids = append(ids, update.Id)
+	}
+
+	var tx *types.Transaction
+	if len(updates) == 1 {
+		price, _ := new(big.Int).SetString(updates[0].NewPrice, 10)
+		tx, err = target.oracle.SetPrice(&transactOpts, common.HexToAddress(updates[0].Asset), price)
+	} else {
+		// the oracle keys prices by the asset address as uint256
+		assets := make([]*big.Int, 0, len(updates))
+		prices := make([]*big.Int, 0, len(updates))
+		for _, update := range updates {
+			price, _ := new(big.Int).SetString(update.NewPrice, 10)
+			assets = append(assets, new(big.Int).SetBytes(common.HexToAddress(update.Asset).Bytes()))
+			prices = append(prices, price)
+		}
+		tx, err = target.oracle.SetPrices(&transactOpts, assets, prices)
+	}
+	if err != nil {
+		log.Logger.Sugar().Error("OraclePusher send err ", chainId, " ", err)
+		for _, update := range updates {
+			_ = models.NewOracleUpdate().Finish(update.Id, models.OracleUpdateFailed, err.Error())
+		}
+		return
+	}
+	for i := range updates {
+		updates[i].TxHash = tx.Hash().String()
+	}
+	err = models.NewOracleUpdate().SaveTxHash(ids, tx.Hash().String())
+	if err != nil {
+		// the rows stay pending without a hash, the receipt below still finishes them
+		log.Logger.Sugar().Error("OraclePusher SaveTxHash err ", chainId, " ", tx.Hash().String(), " ", err)
+	}
+	log.Logger.Sugar().Info("OraclePusher sent ", chainId, " ", len(updates), " prices ", tx.Hash().String())
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), target.receiptTimeout)
+	defer waitCancel()
+	receipt, err := bind.WaitMined(waitCtx, target.conn, tx)
+	if err != nil {
+		// kept pending, the next run reconciles it by hash
+		log.Logger.Sugar().Error("OraclePusher WaitMined err ", chainId, " ", tx.Hash().String(), " ", err)
+		return
+	}
+	s.FinishReceipt(target, updates, receipt)
+}
+
+// Reconcile Finish the pending transactions of a chain, false if one is still unknown to the node
+// or a push of an earlier run may still be sending
+func (s *OraclePusher) Reconcile(target *oracleTarget) bool {
+	unsent, err := models.NewOracleUpdate().PendingWithoutTxHash(target.chain.ChainId)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return false
+	}
+	for _, update := range unsent {
+		if time.Now().Unix()-update.PushedAt < int64((target.sendTimeout + target.receiptTimeout).Seconds()) {
+			log.Logger.Sugar().Info("OraclePusher push without hash ", target.chain.ChainId, " ", update.Id)
+			return false
+		}
+		// the send or the hash write did not finish, the price is checked again
+		_ = models.NewOracleUpdate().Finish(update.Id, models.OracleUpdateFailed, "no transaction hash saved")
+	}
+
+	txHashes, err := models.NewOracleUpdate().PendingTxHashes(target.chain.ChainId)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return false
+	}
+	for _, txHash := range txHashes {
+		updates, err := models.NewOracleUpdate().ListByTxHash(txHash)
+		if err != nil {
+			log.Logger.Error(err.Error())
+			return false
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), target.sendTimeout)
+		receipt, err := target.conn.TransactionReceipt(ctx, common.HexToHash(txHash))
+		isPending := false
+		if errors.Is(err, ethereum.NotFound) {
+			var txErr error
+			_, isPending, txErr = target.conn.TransactionByHash(ctx, common.HexToHash(txHash))
+			if txErr != nil && !errors.Is(txErr, ethereum.NotFound) {
+				err = txErr
+			}
+		}
+		cancel()
+		if errors.Is(err, ethereum.NotFound) {
+			if isPending {
+				log.Logger.Sugar().Info("OraclePusher pending push ", target.chain.ChainId, " ", txHash)
+				return false
+			}
+			// dropped by the node, the prices are checked again
+			for _, update := range updates {
+				_ = models.NewOracleUpdate().Finish(update.Id, models.OracleUpdateFailed, "transaction not found")
+			}
+			continue
+		}
+		if err != nil {
+			log.Logger.Sugar().Error("OraclePusher TransactionReceipt err ", target.chain.ChainId, " ", txHash, " ", err)
+			return false
+		}
+		s.FinishReceipt(target, updates, receipt)
+	}
+	return true
+}
+
+// FinishReceipt Save the outcome of a mined push, an asset only succeeds if the oracle returns the pushed price
+func (s *OraclePusher) FinishReceipt(target *oracleTarget, updates []models.OracleUpdate, receipt *types.Receipt) {
+	oraclePrices := &OraclePriceSource{}
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		assets := make([]string, 0, len(updates))
+		for _, update := range updates {
+			assets = append(assets, update.Asset)
+		}
+		oraclePrices.Prefetch(target.chain, assets)
+	}
+	for _, update := range updates {
+		status, errMsg := models.OracleUpdateSuccess, ""
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			status, errMsg = models.OracleUpdateFailed, "transaction reverted"
+		} else {
+			price, err := oraclePrices.Price(target.chain, &models.TokenInfo{Token: update.Asset, ChainId: target.chain.ChainId})
+			if err != nil {
+				status, errMsg = models.OracleUpdateFailed, "GetPrice err "+err.Error()
+			} else if price.String() != update.NewPrice {
+				status, errMsg = models.OracleUpdateFailed, fmt.Sprintf("oracle price %s after the push", price.String())
+			}
+		}
+		err := models.NewOracleUpdate().Finish(update.Id, status, errMsg)
+		if err != nil {
+			log.Logger.Error(err.Error())
+		}
+		log.Logger.Sugar().Info("OraclePusher ", target.chain.ChainId, " ", update.Asset, " ", update.NewPrice, " ", receipt.TxHash.String(), " ", status)
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"pledge-backend/contract/bindings"
 	"pledge-backend/db"
 	"pledge-backend/log"
+	"pledge-backend/multicall"
+	"pledge-backend/pubsub"
 	"pledge-backend/schedule/models"
 	"pledge-backend/utils"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type poolService struct{}
@@ -23,44 +25,111 @@ func NewPool() *poolService {
 
 func (s *poolService) UpdateAllPoolInfo() {
 
-	s.UpdatePoolInfo(config.Config.TestNet.PledgePoolToken, config.Config.TestNet.NetUrl, config.Config.TestNet.ChainId)
+	for _, chain := range config.EnabledChains() {
+		s.UpdatePoolInfo(chain.PledgePoolToken, chain.ChainId)
+	}
+}
 
-	// s.UpdatePoolInfo(config.Config.MainNet.PledgePoolToken, config.Config.MainNet.NetUrl, config.Config.MainNet.ChainId)
+// poolBaseInfo outputs of poolBaseInfo(uint256)
+type poolBaseInfo struct {
+	SettleTime             *big.Int
+	EndTime                *big.Int
+	InterestRate           *big.Int
+	MaxSupply              *big.Int
+	LendSupply             *big.Int
+	BorrowSupply           *big.Int
+	MartgageRate           *big.Int
+	LendToken              common.Address
+	BorrowToken            common.Address
+	State                  uint8
+	SpCoin                 common.Address
+	JpCoin                 common.Address
+	AutoLiquidateThreshold *big.Int
+}
 
+// poolDataInfo outputs of poolDataInfo(uint256)
+type poolDataInfo struct {
+	SettleAmountLend       *big.Int
+	SettleAmountBorrow     *big.Int
+	FinishAmountLend       *big.Int
+	FinishAmountBorrow     *big.Int
+	LiquidationAmounLend   *big.Int
+	LiquidationAmounBorrow *big.Int
 }
 
-func (s *poolService) UpdatePoolInfo(contractAddress, network, chainId string) {
+// UpdatePoolInfo Read the fees and every pool through multicall at one block and save the pools that changed
+func (s *poolService) UpdatePoolInfo(contractAddress, chainId string) {
 
-	log.Logger.Sugar().Info("UpdatePoolInfo ", contractAddress+" "+network)
-	ethereumConn, err := ethclient.Dial(network)
+	log.Logger.Sugar().Info("UpdatePoolInfo ", contractAddress+" "+chainId)
+	chain, ok := config.GetChain(chainId)
+	if !ok {
+		log.Logger.Sugar().Error("UpdatePoolInfo chain_id err ", chainId)
+		return
+	}
+	reader, err := multicall.NewReader(&chain)
 	if nil != err {
 		log.Logger.Error(err.Error())
 		return
 	}
-	pledgePoolToken, err := bindings.NewPledgePoolToken(common.HexToAddress(contractAddress), ethereumConn)
+	pledgePoolAbi, err := bindings.PledgePoolTokenMetaData.GetAbi()
 	if nil != err {
 		log.Logger.Error(err.Error())
 		return
 	}
+	pledgePool := common.HexToAddress(contractAddress)
 
-	// borrowFee
-	borrowFee, err := pledgePoolToken.PledgePoolTokenCaller.BorrowFee(nil)
-
-	// lendFee
-	lendFee, err := pledgePoolToken.PledgePoolTokenCaller.LendFee(nil)
+	// borrowFee, lendFee, poolLength
+	results, err := reader.Aggregate([]multicall.Call{
+		{Target: pledgePool, Abi: pledgePoolAbi, Method: "borrowFee"},
+		{Target: pledgePool, Abi: pledgePoolAbi, Method: "lendFee"},
+		{Target: pledgePool, Abi: pledgePoolAbi, Method: "poolLength"},
+	})
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return
+	}
+	// the fees go into every pool, without them no pool is updated rather than saved with 0 fees
+	borrowFee, lendFee, pLength := new(big.Int), new(big.Int), new(big.Int)
+	err = results[0].Unpack(&borrowFee)
+	if nil != err {
+		log.Logger.Sugar().Error("UpdatePoolInfo borrowFee err ", chainId, " ", err)
+		return
+	}
+	err = results[1].Unpack(&lendFee)
+	if nil != err {
+		log.Logger.Sugar().Error("UpdatePoolInfo lendFee err ", chainId, " ", err)
+		return
+	}
+	err = results[2].Unpack(&pLength)
+	if nil != err {
+		log.Logger.Error(err.Error())
+		return
+	}
 
-	//poolLength
-	pLength, err := pledgePoolToken.PledgePoolTokenCaller.PoolLength(nil)
+	// poolBaseInfo and poolDataInfo of every pool
+	calls := make([]multicall.Call, 0, 2*pLength.Int64())
+	for i := int64(0); i < pLength.Int64(); i++ {
+		calls = append(calls,
+			multicall.Call{Target: pledgePool, Abi: pledgePoolAbi, Method: "poolBaseInfo", Args: []interface{}{big.NewInt(i)}},
+			multicall.Call{Target: pledgePool, Abi: pledgePoolAbi, Method: "poolDataInfo", Args: []interface{}{big.NewInt(i)}},
+		)
+	}
+	results, err = reader.Aggregate(calls)
 	if nil != err {
 		log.Logger.Error(err.Error())
 		return
 	}
 
+	// base and data of every pool are stamped with the block they were read at
+	blockNumber, blockHash := reader.BlockNumber.Uint64(), reader.BlockHash.Hex()
+	unchangedBases := make([]string, 0)
+	unchangedData := make([]string, 0)
 	for i := 0; i <= int(pLength.Int64())-1; i++ {
 
 		log.Logger.Sugar().Info("UpdatePoolInfo ", i)
 		poolId := utils.IntToString(i + 1)
-		baseInfo, err := pledgePoolToken.PledgePoolTokenCaller.PoolBaseInfo(nil, big.NewInt(int64(i)))
+		baseInfo := poolBaseInfo{}
+		err = results[2*i].Unpack(&baseInfo)
 		if err != nil {
 			log.Logger.Sugar().Info("UpdatePoolInfo PoolBaseInfo err", poolId, err)
 			continue
@@ -105,43 +174,77 @@ func (s *poolService) UpdatePoolInfo(contractAddress, network, chainId string) {
 		}
 
 		hasInfoData, byteBaseInfoStr, baseInfoMd5Str := s.GetPoolMd5(&poolBase, "base_info:pool_"+chainId+"_"+poolId)
-		if !hasInfoData || (baseInfoMd5Str != byteBaseInfoStr) { // have new data
+		baseChanged := !hasInfoData || (baseInfoMd5Str != byteBaseInfoStr)
+		// stamped after the md5 so a new block alone is not a change
+		poolBase.BlockNumber, poolBase.BlockHash = blockNumber, blockHash
+		if baseChanged { // have new data
 			//tokenInfo
 			err = models.NewPoolBase().SavePoolBase(chainId, poolId, &poolBase)
 			if err != nil {
-				log.Logger.Sugar().Error("SavePoolBase err ", chainId, poolId)
+				// without the md5 the next run saves it again
+				log.Logger.Sugar().Error("SavePoolBase err ", chainId, poolId, err)
+			} else {
+				_ = pubsub.Publish(pubsub.PoolTopic(chainId, poolId), poolBase)
+				_ = db.RedisSet("base_info:pool_"+chainId+"_"+poolId, baseInfoMd5Str, 60*30) //The expiration time is set to prevent hsah collision
 			}
-			_ = db.RedisSet("base_info:pool_"+chainId+"_"+poolId, baseInfoMd5Str, 60*30) //The expiration time is set to prevent hsah collision
+		} else {
+			unchangedBases = append(unchangedBases, poolId)
 		}
 
-		dataInfo, err := pledgePoolToken.PledgePoolTokenCaller.PoolDataInfo(nil, big.NewInt(int64(i)))
+		dataInfo := poolDataInfo{}
+		err = results[2*i+1].Unpack(&dataInfo)
 		if err != nil {
-			log.Logger.Sugar().Info("UpdatePoolInfo PoolBaseInfo err", poolId, err)
+			log.Logger.Sugar().Info("UpdatePoolInfo PoolDataInfo err", poolId, err)
 			continue
 		}
 
-		hasPoolData, byteDataInfoStr, dataInfoMd5Str := s.GetPoolMd5(&poolBase, "data_info:pool_"+chainId+"_"+poolId)
-		if !hasPoolData || (dataInfoMd5Str != byteDataInfoStr) { // have new data
-			poolData := models.PoolData{
-				PoolId:                 poolId,
-				ChainId:                chainId,
-				FinishAmountBorrow:     dataInfo.FinishAmountBorrow.String(),
-				FinishAmountLend:       dataInfo.FinishAmountLend.String(),
-				LiquidationAmounBorrow: dataInfo.LiquidationAmounBorrow.String(),
-				LiquidationAmounLend:   dataInfo.LiquidationAmounLend.String(),
-				SettleAmountBorrow:     dataInfo.SettleAmountBorrow.String(),
-				SettleAmountLend:       dataInfo.SettleAmountLend.String(),
-			}
+		poolData := models.PoolData{
+			PoolId:                 poolId,
+			ChainId:                chainId,
+			FinishAmountBorrow:     dataInfo.FinishAmountBorrow.String(),
+			FinishAmountLend:       dataInfo.FinishAmountLend.String(),
+			LiquidationAmounBorrow: dataInfo.LiquidationAmounBorrow.String(),
+			LiquidationAmounLend:   dataInfo.LiquidationAmounLend.String(),
+			SettleAmountBorrow:     dataInfo.SettleAmountBorrow.String(),
+			SettleAmountLend:       dataInfo.SettleAmountLend.String(),
+		}
+
+		hasPoolData, byteDataInfoStr, dataInfoMd5Str := s.GetPoolMd5(&poolData, "data_info:pool_"+chainId+"_"+poolId)
+		dataChanged := !hasPoolData || (dataInfoMd5Str != byteDataInfoStr)
+		poolData.BlockNumber, poolData.BlockHash = blockNumber, blockHash
+		if dataChanged { // have new data
 			err = models.NewPoolData().SavePoolData(chainId, poolId, &poolData)
 			if err != nil {
-				log.Logger.Sugar().Error("SavePoolData err ", chainId, poolId)
+				log.Logger.Sugar().Error("SavePoolData err ", chainId, poolId, err)
+			} else {
+				_ = pubsub.Publish(pubsub.PoolDataTopic(chainId, poolId), poolData)
+				_ = db.RedisSet("data_info:pool_"+chainId+"_"+poolId, dataInfoMd5Str, 60*30) //The expiration time is set to prevent hsah collision
+			}
+		} else {
+			unchangedData = append(unchangedData, poolId)
+		}
+
+		// history for the time series api, an expired md5 also writes one so there is at least one point every 30 minutes
+		if baseChanged || dataChanged {
+			err = models.NewPoolSnapshot().SavePoolSnapshot(&poolBase, &poolData, time.Now().Unix())
+			if err != nil {
+				log.Logger.Sugar().Error("SavePoolSnapshot err ", chainId, poolId, err)
 			}
-			_ = db.RedisSet("data_info:pool_"+chainId+"_"+poolId, dataInfoMd5Str, 60*30) //The expiration time is set to prevent hsah collision
 		}
 	}
+
+	// the pools that did not change only move to the new block
+	err = models.NewPoolBase().SaveBlock(chainId, unchangedBases, blockNumber, blockHash)
+	if err != nil {
+		log.Logger.Sugar().Error("SavePoolBase block err ", chainId, err)
+	}
+	err = models.NewPoolData().SaveBlock(chainId, unchangedData, blockNumber, blockHash)
+	if err != nil {
+		log.Logger.Sugar().Error("SavePoolData block err ", chainId, err)
+	}
 }
 
-func (s *poolService) GetPoolMd5(baseInfo *models.PoolBase, key string) (bool, string, string) {
+func (s *poolService) GetPoolMd5(baseInfo interface{}, key string) (bool, string, string) {
 	baseInfoBytes, _ := json.Marshal(baseInfo)
 	baseInfoMd5Str := utils.Md5(string(baseInfoBytes))
 	resInfoBytes, _ := db.RedisGet(key)
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"pledge-backend/api/models/kucoin"
+	"pledge-backend/config"
+	"pledge-backend/contract/bindings"
+	"pledge-backend/db"
+	"pledge-backend/log"
+	"pledge-backend/multicall"
+	serviceCommon "pledge-backend/schedule/common"
+	"pledge-backend/schedule/models"
+	"pledge-backend/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+// minDeviationSources prices needed before max_deviation rejects any, the median of two prices is their mean
+// so both would deviate by the same percent and be accepted or rejected together
+const minDeviationSources = 3
+
+// ErrPriceUnsupported a source has no price for the token, it is left out of the aggregation
+var ErrPriceUnsupported = errors.New("price source does not support the token")
+
+// PriceSource gives the price of a token in the oracle unit, usd * 1e8
+type PriceSource interface {
+	Name() string
+	Price(chain *config.ChainConfig, token *models.TokenInfo) (decimal.Decimal, error)
+}
+
+// NewPriceSources Sources listed in the price config, the oracle alone if none is listed
+func NewPriceSources() []PriceSource {
+	names := config.Config.Price.Sources
+	if len(names) == 0 {
+		names = []string{"oracle"}
+	}
+	sources := make([]PriceSource, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "oracle":
+			sources = append(sources, &OraclePriceSource{})
+		case "kucoin":
+			sources = append(sources, &KucoinPriceSource{})
+		default:
+			found := false
+			for _, httpSource := range config.Config.Price.HttpSources {
+				if httpSource.Name == name {
+					sources = append(sources, &HttpPriceSource{Config: httpSource})
+					found = true
+					break
+				}
+			}
+			if !found {
+				log.Logger.Sugar().Error("unknown price source ", name)
+			}
+		}
+	}
+	return sources
+}
+
+// BatchPriceSource a source that reads the prices of all the tokens of a chain at once, before Price is asked for each
+type BatchPriceSource interface {
+	Prefetch(chain *config.ChainConfig, tokens []string)
+}
+
+// OraclePriceSource price read from the BscPledgeOracle of the chain
+type OraclePriceSource struct {
+	prices map[string]decimal.Decimal   // chainId:token of the prefetched prices
+	blocks map[string]*multicall.Reader // chainId of the prefetched block
+}
+
+func (o *OraclePriceSource) Name() string {
+	return "oracle"
+}
+
+// Prefetch Read the oracle price of the tokens in multicall batches at one block, a token that fails is read alone by Price
+func (o *OraclePriceSource) Prefetch(chain *config.ChainConfig, tokens []string) {
+	if o.prices == nil {
+		o.prices = make(map[string]decimal.Decimal)
+		o.blocks = make(map[string]*multicall.Reader)
+	}
+	reader, err := multicall.NewReader(chain)
+	if err != nil {
+		log.Logger.Sugar().Error("OraclePriceSource Prefetch err ", chain.ChainId, " ", err)
+		return
+	}
+	o.blocks[chain.ChainId] = reader
+	oracleAbi, err := bindings.BscPledgeOracleMainnetTokenMetaData.GetAbi()
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return
+	}
+	calls := make([]multicall.Call, 0, len(tokens))
+	for _, token := range tokens {
+		calls = append(calls, multicall.Call{
+			Target: common.HexToAddress(chain.OracleToken),
+			Abi:    oracleAbi,
+			Method: "getPrice",
+			Args:   []interface{}{common.HexToAddress(token)},
+		})
+	}
+	results, err := reader.Aggregate(calls)
+	if err != nil {
+		log.Logger.Sugar().Error("OraclePriceSource Prefetch err ", chain.ChainId, " ", err)
+		return
+	}
+	for i, result := range results {
+		price := new(big.Int)
+		if result.Unpack(&price) == nil {
+			o.prices[chain.ChainId+":"+strings.ToLower(tokens[i])] = decimal.NewFromBigInt(price, 0)
+		}
+	}
+}
+
+// Block Number and hash of the block the prices of a chain were prefetched at, false if they were not
+func (o *OraclePriceSource) Block(chainId string) (uint64, string, bool) {
+	reader, ok := o.blocks[chainId]
+	if !ok {
+		return 0, "", false
+	}
+	return reader.BlockNumber.Uint64(), reader.BlockHash.Hex(), true
+}
+
+func (o *OraclePriceSource) Price(chain *config.ChainConfig, token *models.TokenInfo) (decimal.Decimal, error) {
+	if price, ok := o.prices[chain.ChainId+":"+strings.ToLower(token.Token)]; ok {
+		return price, nil
+	}
+	// at the block of the prefetched prices, by hash like the multicall reader
+	opts := &bind.CallOpts{}
+	if reader, ok := o.blocks[chain.ChainId]; ok {
+		opts.BlockHash = reader.BlockHash
+	}
+	err, price := NewTokenPrice().GetTokenPrice(chain, token.Token, opts)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return decimal.NewFromInt(price), nil
+}
+
+// KucoinPriceSource exchange price of PLGR stored by the ku-coin producer of the api, only for the plgr token
+type KucoinPriceSource struct{}
+
+func (k *KucoinPriceSource) Name() string {
+	return "kucoin"
+}
+
+func (k *KucoinPriceSource) Price(chain *config.ChainConfig, token *models.TokenInfo) (decimal.Decimal, error) {
+	if !strings.EqualFold(token.Token, chain.PlgrAddress) {
+		return decimal.Zero, ErrPriceUnsupported
+	}
+	updatedAt, _ := db.RedisGetInt64(kucoin.PriceUpdatedAtKey)
+	if updatedAt <= 0 || time.Now().Unix()-updatedAt > config.Config.Kucoin.StaleThreshold {
+		return decimal.Zero, fmt.Errorf("plgr price is stale, last update %d", updatedAt)
+	}
+	priceStr, err := db.RedisGetString(kucoin.PriceKey)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	price, err := decimal.NewFromString(priceStr)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return price.Mul(serviceCommon.RateDecimal), nil
+}
+
+// HttpPriceSource usd price read from a json api, e.g. a local stub
+type HttpPriceSource struct {
+	Config config.HttpPriceSourceConfig
+}
+
+func (h *HttpPriceSource) Name() string {
+	return h.Config.Name
+}
+
+func (h *HttpPriceSource) Price(chain *config.ChainConfig, token *models.TokenInfo) (decimal.Decimal, error) {
+	url := strings.NewReplacer("{chain_id}", chain.ChainId, "{token}", token.Token, "{symbol}", token.Symbol).Replace(h.Config.Url)
+	body, err := utils.HttpGet(url, nil)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(body)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return decimal.Zero, err
+	}
+	for _, key := range strings.Split(h.Config.Field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return decimal.Zero, fmt.Errorf("field %s not found", h.Config.Field)
+		}
+		value = object[key]
+	}
+
+	var price decimal.Decimal
+	switch v := value.(type) {
+	case json.Number:
+		price, err = decimal.NewFromString(v.String())
+	case string:
+		price, err = decimal.NewFromString(v)
+	case nil:
+		return decimal.Zero, ErrPriceUnsupported
+	default:
+		err = fmt.Errorf("field %s is not a number", h.Config.Field)
+	}
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return price.Mul(serviceCommon.RateDecimal), nil
+}
+
+// PrefetchPrices Let the batch sources read the prices of the tokens of each chain at once
+func (s *TokenPrice) PrefetchPrices(sources []PriceSource, tokens []models.TokenInfo) {
+	chainTokens := make(map[string][]string)
+	for _, t := range tokens {
+		if t.Token != "" {
+			chainTokens[t.ChainId] = append(chainTokens[t.ChainId], t.Token)
+		}
+	}
+	for chainId, chainToken := range chainTokens {
+		chain, ok := config.GetChain(chainId)
+		if !ok {
+			continue
+		}
+		for _, source := range sources {
+			if batchSource, ok := source.(BatchPriceSource); ok {
+				batchSource.Prefetch(&chain, chainToken)
+			}
+		}
+	}
+}
+
+// AggregatePrice Median of the source prices, without the sources deviating more than max_deviation percent from the
+// median of all of them, which only applies from minDeviationSources prices on. The price of every source is recorded
+// with whether it was accepted.
+func (s *TokenPrice) AggregatePrice(sources []PriceSource, chain *config.ChainConfig, token *models.TokenInfo) (int64, error) {
+	records := make([]models.TokenPriceSource, 0, len(sources))
+	prices := make([]decimal.Decimal, 0, len(sources))
+	for _, source := range sources {
+		price, err := source.Price(chain, token)
+		if errors.Is(err, ErrPriceUnsupported) {
+			continue
+		}
+		if err == nil && !price.IsPositive() {
+			err = fmt.Errorf("invalid price %s", price.String())
+		}
+		record := models.TokenPriceSource{ChainId: chain.ChainId, Token: token.Token, Source: source.Name()}
+		if err != nil {
+			record.Status = models.PriceSourceFailed
+			record.ErrMsg = err.Error()
+		} else {
+			price = price.Truncate(0)
+			record.Price = price.String()
+			prices = append(prices, price)
+		}
+		records = append(records, record)
+	}
+
+	aggregate, err := aggregateMedian(records, prices)
+
+	saveErr := models.NewTokenPriceSource().SaveSources(records)
+	if saveErr != nil {
+		log.Logger.Sugar().Error("AggregatePrice SaveSources err ", saveErr)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return aggregate.IntPart(), nil
+}
+
+// aggregateMedian set the deviation and status of the records that have a price and return the median of the accepted ones
+func aggregateMedian(records []models.TokenPriceSource, prices []decimal.Decimal) (decimal.Decimal, error) {
+	if len(prices) == 0 {
+		return decimal.Zero, errors.New("no price source returned a price")
+	}
+	median := medianPrice(prices)
+
+	maxDeviation := decimal.NewFromFloat(config.Config.Price.MaxDeviation)
+	accepted := make([]decimal.Decimal, 0, len(prices))
+	for i := range records {
+		if records[i].Status == models.PriceSourceFailed {
+			continue
+		}
+		price, _ := decimal.NewFromString(records[i].Price)
+		deviation := price.Sub(median).Abs().Div(median).Mul(decimal.NewFromInt(100))
+		records[i].Deviation = deviation.StringFixed(4)
+		if maxDeviation.IsPositive() && len(prices) >= minDeviationSources && deviation.GreaterThan(maxDeviation) {
+			records[i].Status = models.PriceSourceRejected
+			continue
+		}
+		records[i].Status = models.PriceSourceAccepted
+		accepted = append(accepted, price)
+	}
+
+	minSources := config.Config.Price.MinSources
+	if minSources < 1 {
+		minSources = 1
+	}
+	if len(accepted) < minSources {
+		return decimal.Zero, fmt.Errorf("%d price sources accepted, %d needed", len(accepted), minSources)
+	}
+	return medianPrice(accepted), nil
+}
+
+func medianPrice(prices []decimal.Decimal) decimal.Decimal {
+	sorted := make([]decimal.Decimal, len(prices))
+	copy(sorted, prices)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].LessThan(sorted[j])
+	})
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return sorted[middle-1].Add(sorted[middle]).Div(decimal.NewFromInt(2))
+}
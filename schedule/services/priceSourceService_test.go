@@ -0,0 +1,126 @@
+package services
+
+import (
+	"pledge-backend/config"
+	"pledge-backend/schedule/models"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMedianPrice(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []int64
+		want   string
+	}{
+		{name: "one price", prices: []int64{100}, want: "100"},
+		{name: "two prices are averaged", prices: []int64{100, 200}, want: "150"},
+		{name: "odd count", prices: []int64{300, 100, 200}, want: "200"},
+		{name: "even count", prices: []int64{400, 100, 300, 200}, want: "250"},
+		{name: "equal prices", prices: []int64{7, 7, 7}, want: "7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prices := make([]decimal.Decimal, 0, len(tt.prices))
+			for _, price := range tt.prices {
+				prices = append(prices, decimal.NewFromInt(price))
+			}
+			if got := medianPrice(prices); got.String() != tt.want {
+				t.Errorf("medianPrice() = %s, want %s", got, tt.want)
+			}
+			if tt.prices[0] != prices[0].IntPart() {
+				t.Errorf("medianPrice() reordered its input")
+			}
+		})
+	}
+}
+
+func TestAggregateMedian(t *testing.T) {
+	priceConfig := config.Config.Price
+	defer func() {
+		config.Config.Price = priceConfig
+	}()
+
+	tests := []struct {
+		name         string
+		maxDeviation float64
+		minSources   int
+		prices       []string // "" is a failed source
+		want         string
+		wantStatus   []string
+		wantErr      bool
+	}{
+		{
+			name: "all accepted", maxDeviation: 5, minSources: 1,
+			prices: []string{"100", "101", "102"}, want: "101",
+			wantStatus: []string{models.PriceSourceAccepted, models.PriceSourceAccepted, models.PriceSourceAccepted},
+		},
+		{
+			name: "outlier rejected", maxDeviation: 5, minSources: 1,
+			prices: []string{"100", "101", "150"}, want: "100.5",
+			wantStatus: []string{models.PriceSourceAccepted, models.PriceSourceAccepted, models.PriceSourceRejected},
+		},
+		{
+			name: "two prices are not checked for deviation", maxDeviation: 5, minSources: 2,
+			prices: []string{"100", "150"}, want: "125",
+			wantStatus: []string{models.PriceSourceAccepted, models.PriceSourceAccepted},
+		},
+		{
+			name: "failed source left out", maxDeviation: 5, minSources: 1,
+			prices: []string{"100", "", "102"}, want: "101",
+			wantStatus: []string{models.PriceSourceAccepted, models.PriceSourceFailed, models.PriceSourceAccepted},
+		},
+		{
+			name: "no deviation limit", maxDeviation: 0, minSources: 1,
+			prices: []string{"100", "101", "150"}, want: "101",
+			wantStatus: []string{models.PriceSourceAccepted, models.PriceSourceAccepted, models.PriceSourceAccepted},
+		},
+		{
+			name: "too few accepted", maxDeviation: 5, minSources: 3,
+			prices: []string{"100", "101", "150"}, wantErr: true,
+			wantStatus: []string{models.PriceSourceAccepted, models.PriceSourceAccepted, models.PriceSourceRejected},
+		},
+		{
+			name: "no price", maxDeviation: 5, minSources: 1,
+			prices: []string{""}, wantErr: true,
+			wantStatus: []string{models.PriceSourceFailed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Config.Price.MaxDeviation = tt.maxDeviation
+			config.Config.Price.MinSources = tt.minSources
+
+			records := make([]models.TokenPriceSource, 0, len(tt.prices))
+			prices := make([]decimal.Decimal, 0, len(tt.prices))
+			for _, price := range tt.prices {
+				if price == "" {
+					records = append(records, models.TokenPriceSource{Status: models.PriceSourceFailed})
+					continue
+				}
+				records = append(records, models.TokenPriceSource{Price: price})
+				prices = append(prices, decimal.RequireFromString(price))
+			}
+
+			got, err := aggregateMedian(records, prices)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("aggregateMedian() = %s, want an error", got)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("aggregateMedian() err = %v", err)
+				}
+				if got.String() != tt.want {
+					t.Errorf("aggregateMedian() = %s, want %s", got, tt.want)
+				}
+			}
+			for i, record := range records {
+				if record.Status != tt.wantStatus[i] {
+					t.Errorf("record %d status = %s, want %s", i, record.Status, tt.wantStatus[i])
+				}
+			}
+		})
+	}
+}
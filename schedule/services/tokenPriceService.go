@@ -1,25 +1,20 @@
 package services
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"math/big"
+	"pledge-backend/chainclient"
 	"pledge-backend/config"
 	"pledge-backend/contract/bindings"
 	"pledge-backend/db"
 	"pledge-backend/log"
-	serviceCommon "pledge-backend/schedule/common"
+	"pledge-backend/pubsub"
 	"pledge-backend/schedule/models"
 	"pledge-backend/utils"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -29,10 +24,18 @@ func NewTokenPrice() *TokenPrice {
 	return &TokenPrice{}
 }
 
-// UpdateContractPrice update contract price
+// UpdateContractPrice update the token_info price with the aggregate of the configured price sources
 func (s *TokenPrice) UpdateContractPrice() {
 	var tokens []models.TokenInfo
 	db.Mysql.Table("token_info").Find(&tokens)
+	sources := NewPriceSources()
+	s.PrefetchPrices(sources, tokens)
+	var oracle *OraclePriceSource
+	for _, source := range sources {
+		if o, ok := source.(*OraclePriceSource); ok {
+			oracle = o
+		}
+	}
 	for _, t := range tokens {
 
 		var err error
@@ -42,22 +45,12 @@ func (s *TokenPrice) UpdateContractPrice() {
 			log.Logger.Sugar().Error("UpdateContractPrice token empty ", t.Symbol, t.ChainId)
 			continue
 		} else {
-			if t.ChainId == config.Config.TestNet.ChainId {
-				err, price = s.GetTestNetTokenPrice(t.Token)
-			} else if t.ChainId == "56" {
-				// if strings.ToUpper(t.Token) == config.Config.MainNet.PlgrAddress { // get PLGR price from ku-coin(Only main network price)
-				// 	priceStr, _ := db.RedisGetString("plgr_price")
-				// 	priceF, _ := decimal.NewFromString(priceStr)
-				// 	e8 := decimal.NewFromInt(100000000)
-				// 	priceF = priceF.Mul(e8)
-				// 	price = priceF.IntPart()
-				// } else {
-				// 	err, price = s.GetMainNetTokenPrice(t.Token)
-				// }
-
-				//err, price = s.GetMainNetTokenPrice(t.Token)
-
+			chain, ok := config.GetChain(t.ChainId)
+			if !ok {
+				log.Logger.Sugar().Error("UpdateContractPrice chain_id err ", t.Symbol, t.ChainId)
+				continue
 			}
+			price, err = s.AggregatePrice(sources, &chain, &t)
 
 			if err != nil {
 				log.Logger.Sugar().Error("UpdateContractPrice err ", t.Symbol, t.ChainId, err)
@@ -71,31 +64,53 @@ func (s *TokenPrice) UpdateContractPrice() {
 			continue
 		}
 
+		// the oracle prices of the chain were read at one block, the row is stamped with it even if the price is unchanged
+		var blockNumber uint64
+		var blockHash string
+		if oracle != nil {
+			blockNumber, blockHash, _ = oracle.Block(t.ChainId)
+		}
+		if !hasNewData && blockNumber > 0 {
+			err = models.NewTokenInfo().SaveBlock(t.ChainId, t.Token, blockNumber, blockHash)
+			if err != nil {
+				log.Logger.Sugar().Error("UpdateContractPrice SaveBlock err ", err)
+			}
+		}
+
 		if hasNewData {
-			err = s.SavePriceData(t.Token, t.ChainId, utils.Int64ToString(price))
+			err = s.SavePriceData(t.Token, t.ChainId, utils.Int64ToString(price), blockNumber, blockHash)
 			if err != nil {
 				log.Logger.Sugar().Error("UpdateContractPrice SavePriceData err ", err)
 				continue
 			}
+			err = models.NewTokenPrice().SaveTokenPrice(t.ChainId, t.Token, utils.Int64ToString(price), time.Now().Unix())
+			if err != nil {
+				log.Logger.Sugar().Error("UpdateContractPrice SaveTokenPrice err ", err)
+			}
+			_ = pubsub.Publish(pubsub.PriceTopic(t.ChainId, t.Token), models.RedisTokenInfo{
+				Token:   t.Token,
+				ChainId: t.ChainId,
+				Price:   utils.Int64ToString(price),
+			})
 		}
 	}
 }
 
-// GetMainNetTokenPrice get contract price on main net
-func (s *TokenPrice) GetMainNetTokenPrice(token string) (error, int64) {
-	ethereumConn, err := ethclient.Dial(config.Config.MainNet.NetUrl)
+// GetTokenPrice get contract price from the oracle of a chain, at the block of opts or the latest block
+func (s *TokenPrice) GetTokenPrice(chain *config.ChainConfig, token string, opts *bind.CallOpts) (error, int64) {
+	ethereumConn, err := chainclient.GetClient(chain.ChainId)
 	if nil != err {
 		log.Logger.Error(err.Error())
 		return err, 0
 	}
 
-	bscPledgeOracleMainNetToken, err := bindings.NewBscPledgeOracleMainnetToken(common.HexToAddress(config.Config.MainNet.BscPledgeOracleToken), ethereumConn)
+	bscPledgeOracleToken, err := bindings.NewBscPledgeOracleMainnetToken(common.HexToAddress(chain.OracleToken), ethereumConn)
 	if nil != err {
 		log.Logger.Error(err.Error())
 		return err, 0
 	}
 
-	price, err := bscPledgeOracleMainNetToken.GetPrice(nil, common.HexToAddress(token))
+	price, err := bscPledgeOracleToken.GetPrice(opts, common.HexToAddress(token))
 	if err != nil {
 		log.Logger.Error(err.Error())
 		return err, 0
@@ -104,29 +119,6 @@ func (s *TokenPrice) GetMainNetTokenPrice(token string) (error, int64) {
 	return nil, price.Int64()
 }
 
-// GetTestNetTokenPrice get contract price on test net
-func (s *TokenPrice) GetTestNetTokenPrice(token string) (error, int64) {
-	ethereumConn, err := ethclient.Dial(config.Config.TestNet.NetUrl)
-	if nil != err {
-		log.Logger.Error(err.Error())
-		return err, 0
-	}
-
-	bscPledgeOracleTestnetToken, err := bindings.NewBscPledgeOracleTestnetToken(common.HexToAddress(config.Config.TestNet.BscPledgeOracleToken), ethereumConn)
-	if nil != err {
-		log.Logger.Error(err.Error())
-		return err, 0
-	}
-
-	price, err := bscPledgeOracleTestnetToken.GetPrice(nil, common.HexToAddress(token))
-	if nil != err {
-		log.Logger.Error(err.Error())
-		return err, 0
-	}
-
-	return nil, price.Int64()
-}
-
 // CheckPriceData Saving price data to redis if it has new price
 func (s *TokenPrice) CheckPriceData(token, chainId, price string) (bool, error) {
 	redisKey := "token_info:" + chainId + ":" + token
@@ -190,15 +182,20 @@ func (s *TokenPrice) CheckTokenInfo(token, chainId string) error {
 	return nil
 }
 
-// SavePriceData Saving price data to mysql if it has new price
-func (s *TokenPrice) SavePriceData(token, chainId, price string) error {
+// SavePriceData Saving price data to mysql if it has new price, with the block it was read at if it is known
+func (s *TokenPrice) SavePriceData(token, chainId, price string, blockNumber uint64, blockHash string) error {
 
 	nowDateTime := utils.GetCurDateTimeFormat()
 
-	err := db.Mysql.Table("token_info").Where("token=? and chain_id=? ", token, chainId).Updates(map[string]interface{}{
+	values := map[string]interface{}{
 		"price":      price,
 		"updated_at": nowDateTime,
-	}).Debug().Error
+	}
+	if blockNumber > 0 {
+		values["block_number"] = blockNumber
+		values["block_hash"] = blockHash
+	}
+	err := db.Mysql.Table("token_info").Where("token=? and chain_id=? ", token, chainId).Updates(values).Debug().Error
 	if err != nil {
 		log.Logger.Sugar().Error("UpdateContractPrice SavePriceData err ", err)
 		return err
@@ -207,108 +204,22 @@ func (s *TokenPrice) SavePriceData(token, chainId, price string) error {
 	return nil
 }
 
-// SavePlgrPrice Saving price data to mysql if it has new price
-func (s *TokenPrice) SavePlgrPrice() {
-	priceStr, _ := db.RedisGetString("plgr_price")
-	priceF, _ := decimal.NewFromString(priceStr)
-	e8 := decimal.NewFromInt(100000000)
-	priceF = priceF.Mul(e8)
-	price := priceF.IntPart()
-
-	ethereumConn, err := ethclient.Dial(config.Config.MainNet.NetUrl)
-	if nil != err {
-		log.Logger.Error(err.Error())
-		return
-	}
-	bscPledgeOracleMainNetToken, err := bindings.NewBscPledgeOracleMainnetToken(common.HexToAddress(config.Config.MainNet.BscPledgeOracleToken), ethereumConn)
-	if nil != err {
-		log.Logger.Error(err.Error())
-		return
-	}
-
-	privateKeyEcdsa, err := crypto.HexToECDSA(serviceCommon.PlgrAdminPrivateKey)
-	if err != nil {
-		log.Logger.Error(err.Error())
-		return
-	}
-
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKeyEcdsa, big.NewInt(utils.StringToInt64(config.Config.MainNet.ChainId)))
-	if err != nil {
-		log.Logger.Error(err.Error())
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
-
-	transactOpts := bind.TransactOpts{
-		From:      auth.From,
-		Nonce:     nil,
-		Signer:    auth.Signer, // Method to use for signing the transaction (mandatory)
-		Value:     big.NewInt(0),
-		GasPrice:  nil,
-		GasFeeCap: nil,
-		GasTipCap: nil,
-		GasLimit:  0,
-		Context:   ctx,
-		NoSend:    false, // Do all transact steps but do not send the transaction
-	}
-
-	_, err = bscPledgeOracleMainNetToken.SetPrice(&transactOpts, common.HexToAddress(config.Config.MainNet.PlgrAddress), big.NewInt(price))
-
-	log.Logger.Sugar().Info("SavePlgrPrice ", err)
-
-	a, d := s.GetMainNetTokenPrice(config.Config.MainNet.PlgrAddress)
-	log.Logger.Sugar().Info("GetMainNetTokenPrice ", a, d)
-}
-
-// SavePlgrPriceTestNet  Saving price data to mysql if it has new price
-func (s *TokenPrice) SavePlgrPriceTestNet() {
-
-	price := 22222
-	ethereumConn, err := ethclient.Dial(config.Config.TestNet.NetUrl)
-	if nil != err {
-		log.Logger.Error(err.Error())
-		return
-	}
-	bscPledgeOracleTestNetToken, err := bindings.NewBscPledgeOracleMainnetToken(common.HexToAddress(config.Config.TestNet.BscPledgeOracleToken), ethereumConn)
-	if nil != err {
-		log.Logger.Error(err.Error())
-		return
-	}
-
-	privateKeyEcdsa, err := crypto.HexToECDSA(serviceCommon.PlgrAdminPrivateKey)
-	if err != nil {
-		log.Logger.Error(err.Error())
-		return
-	}
-
-	auth, err := bind.NewKeyedTransactorWithChainID(privateKeyEcdsa, big.NewInt(utils.StringToInt64(config.Config.TestNet.ChainId)))
-	if err != nil {
-		log.Logger.Error(err.Error())
-		return
+// DownsamplePrices Merge the price changes older than raw_retention into hourly candles
+// and the hourly candles older than hourly_retention into daily candles, a retention of 0 keeps the rows
+func (s *TokenPrice) DownsamplePrices() {
+	now := time.Now().Unix()
+	history := config.Config.PriceHistory
+	if history.RawRetention > 0 {
+		err := models.NewTokenPrice().Downsample(models.PriceResolutionRaw, models.PriceResolutionHour, now-history.RawRetention)
+		if err != nil {
+			log.Logger.Sugar().Error("DownsamplePrices hourly err ", err)
+			return
+		}
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
-
-	transactOpts := bind.TransactOpts{
-		From:      auth.From,
-		Nonce:     nil,
-		Signer:    auth.Signer, // Method to use for signing the transaction (mandatory)
-		Value:     big.NewInt(0),
-		GasPrice:  nil,
-		GasFeeCap: nil,
-		GasTipCap: nil,
-		GasLimit:  0,
-		Context:   ctx,
-		NoSend:    false, // Do all transact steps but do not send the transaction
+	if history.HourlyRetention > 0 {
+		err := models.NewTokenPrice().Downsample(models.PriceResolutionHour, models.PriceResolutionDay, now-history.HourlyRetention)
+		if err != nil {
+			log.Logger.Sugar().Error("DownsamplePrices daily err ", err)
+		}
 	}
-
-	_, err = bscPledgeOracleTestNetToken.SetPrice(&transactOpts, common.HexToAddress(config.Config.TestNet.PlgrAddress), big.NewInt(int64(price)))
-
-	log.Logger.Sugar().Info("SavePlgrPrice ", err)
-
-	a, d := s.GetTestNetTokenPrice(config.Config.TestNet.PlgrAddress)
-	fmt.Println(a, d, 5555)
 }
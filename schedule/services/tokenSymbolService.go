@@ -1,21 +1,19 @@
 package services
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
-	"os"
 	"pledge-backend/config"
-	abifile "pledge-backend/contract/abi"
+	"pledge-backend/contract/bindings"
 	"pledge-backend/db"
 	"pledge-backend/log"
+	"pledge-backend/multicall"
 	"pledge-backend/schedule/models"
 	"pledge-backend/utils"
 	"strings"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"gorm.io/gorm"
 )
 
@@ -25,45 +23,61 @@ func NewTokenSymbol() *TokenSymbol {
 	return &TokenSymbol{}
 }
 
-// UpdateContractSymbol get contract symbol
+// TokenMetadata name, symbol and decimals read from an erc20 contract
+type TokenMetadata struct {
+	Name     string
+	Symbol   string
+	Decimals int
+}
+
+// UpdateContractSymbol update the name, symbol and decimals of the tokens from their erc20 contract
 func (s *TokenSymbol) UpdateContractSymbol() {
 	var tokens []models.TokenInfo
 	db.Mysql.Table("token_info").Find(&tokens)
+
+	chainTokens := make(map[string][]string)
+	for _, t := range tokens {
+		if t.Token != "" {
+			chainTokens[t.ChainId] = append(chainTokens[t.ChainId], t.Token)
+		}
+	}
+	chainMetadata := make(map[string]map[string]TokenMetadata)
+	for chainId, chainToken := range chainTokens {
+		chain, ok := config.GetChain(chainId)
+		if !ok {
+			continue
+		}
+		metadata, err := s.GetTokensMetadata(&chain, chainToken)
+		if err != nil {
+			log.Logger.Sugar().Error("UpdateContractSymbol GetTokensMetadata err ", chainId, " ", err)
+			continue
+		}
+		chainMetadata[chainId] = metadata
+	}
+
 	for _, t := range tokens {
 		if t.Token == "" {
 			log.Logger.Sugar().Error("UpdateContractSymbol token empty", t.Symbol, t.ChainId)
 			continue
 		}
-		err := errors.New("")
-		symbol := ""
-		if t.ChainId == config.Config.TestNet.ChainId {
-			err, symbol = s.GetContractSymbolOnTestNet(t.Token, config.Config.TestNet.NetUrl)
-		} else if t.ChainId == config.Config.MainNet.ChainId {
-			if t.AbiFileExist == 0 {
-				err = s.GetRemoteAbiFileByToken(t.Token, t.ChainId)
-				if err != nil {
-					log.Logger.Sugar().Error("UpdateContractSymbol GetRemoteAbiFileByToken err ", t.Symbol, t.ChainId, err)
-					continue
-				}
-			}
-			err, symbol = s.GetContractSymbolOnMainNet(t.Token, config.Config.MainNet.NetUrl)
-		} else {
+		if _, ok := config.GetChain(t.ChainId); !ok {
 			log.Logger.Sugar().Error("UpdateContractSymbol chain_id err ", t.Symbol, t.ChainId)
 			continue
 		}
-		if err != nil {
-			log.Logger.Sugar().Error("UpdateContractSymbol err ", t.Symbol, t.ChainId, err)
+		metadata, ok := chainMetadata[t.ChainId][t.Token]
+		if !ok {
+			log.Logger.Sugar().Error("UpdateContractSymbol no erc20 metadata ", t.Symbol, t.ChainId)
 			continue
 		}
 
-		hasNewData, err := s.CheckSymbolData(t.Token, t.ChainId, symbol)
+		hasNewData, err := s.CheckSymbolData(t.Token, t.ChainId, metadata.Symbol)
 		if err != nil {
 			log.Logger.Sugar().Error("UpdateContractSymbol CheckSymbolData err ", err)
 			continue
 		}
 
-		if hasNewData {
-			err = s.SaveSymbolData(t.Token, t.ChainId, symbol)
+		if hasNewData || t.Name != metadata.Name || t.Decimals != metadata.Decimals {
+			err = s.SaveSymbolData(t.Token, t.ChainId, metadata)
 			if err != nil {
 				log.Logger.Sugar().Error("UpdateContractSymbol SaveSymbolData err ", err)
 				continue
@@ -72,130 +86,75 @@ func (s *TokenSymbol) UpdateContractSymbol() {
 	}
 }
 
-// GetRemoteAbiFileByToken get and save remote abi file on main net
-func (s *TokenSymbol) GetRemoteAbiFileByToken(token, chainId string) error {
-
-	// url := "https://api.bscscan.com/api?module=contract&action=getabi&apikey=HJ3WS4N88QJ6S7PQ8D89BD49IZIFP1JFER&address=" + token
-
-	url := "https://api-sepolia.etherscan.io/api?module=contract&action=getabi&address=" + token
-
-	res, err := utils.HttpGet(url, map[string]string{})
+// GetTokensMetadata Read name, symbol and decimals of the tokens of a chain with the erc20 abi in multicall batches.
+// Tokens like MKR return name and symbol as bytes32, they are decoded with the bytes32 abi when the string decoding fails.
+// A token without a name keeps it empty, a token without symbol or decimals is left out.
+func (s *TokenSymbol) GetTokensMetadata(chain *config.ChainConfig, tokens []string) (map[string]TokenMetadata, error) {
+	reader, err := multicall.NewReader(chain)
 	if err != nil {
-		log.Logger.Error(err.Error())
-		return err
+		return nil, err
 	}
-
-	resStr := s.FormatAbiJsonStr(string(res))
-
-	abiJson := models.AbiJson{}
-	err = json.Unmarshal([]byte(resStr), &abiJson)
+	erc20Abi, err := bindings.Erc20MetaData.GetAbi()
 	if err != nil {
-		log.Logger.Error(err.Error())
-		return err
-	}
-
-	if abiJson.Status != "1" {
-		log.Logger.Sugar().Error("get remote abi file failed: status 0 ", resStr)
-		return errors.New("get remote abi file failed: status 0 ")
+		return nil, err
 	}
-
-	// marshal and format
-	abiJsonBytes, err := json.MarshalIndent(abiJson.Result, "", "\t")
+	erc20Bytes32Abi, err := bindings.Erc20Bytes32MetaData.GetAbi()
 	if err != nil {
-		log.Logger.Error(err.Error())
-		return err
+		return nil, err
 	}
 
-	newAbiFile := abifile.GetCurrentAbPathByCaller() + "/" + token + ".abi"
-
-	err = os.WriteFile(newAbiFile, abiJsonBytes, 0777)
-	if err != nil {
-		log.Logger.Error(err.Error())
-		return err
+	calls := make([]multicall.Call, 0, 3*len(tokens))
+	for _, token := range tokens {
+		address := common.HexToAddress(token)
+		calls = append(calls,
+			multicall.Call{Target: address, Abi: erc20Abi, Method: "symbol"},
+			multicall.Call{Target: address, Abi: erc20Abi, Method: "name"},
+			multicall.Call{Target: address, Abi: erc20Abi, Method: "decimals"},
+		)
 	}
-
-	err = db.Mysql.Table("token_info").Where("token=? and chain_id=?", token, chainId).Updates(map[string]interface{}{
-		"abi_file_exist": 1,
-	}).Debug().Error
+	results, err := reader.Aggregate(calls)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
-}
 
-// FormatAbiJsonStr format the abi string
-func (s *TokenSymbol) FormatAbiJsonStr(result string) string {
-	resStr := strings.Replace(result, `\`, ``, -1)
-	resStr = strings.Replace(result, `\"`, `"`, -1)
-	resStr = strings.Replace(resStr, `"[{`, `[{`, -1)
-	resStr = strings.Replace(resStr, `}]"`, `}]`, -1)
-	return resStr
-}
-
-// GetContractSymbolOnMainNet get contract symbol on main net
-func (s *TokenSymbol) GetContractSymbolOnMainNet(token, network string) (error, string) {
-	ethereumConn, err := ethclient.Dial(network)
-	if nil != err {
-		log.Logger.Sugar().Error("GetContractSymbolOnMainNet err ", token, err)
-		return err, ""
-	}
-	abiStr, err := abifile.GetAbiByToken(token)
-	if err != nil {
-		log.Logger.Sugar().Error("GetContractSymbolOnMainNet err ", token, err)
-		return err, ""
-	}
-	parsed, err := abi.JSON(strings.NewReader(abiStr))
-	if err != nil {
-		log.Logger.Sugar().Error("GetContractSymbolOnMainNet err ", token, err)
-		return err, ""
-	}
-	contract, err := bind.NewBoundContract(common.HexToAddress(token), parsed, ethereumConn, ethereumConn, ethereumConn), nil
-	if err != nil {
-		log.Logger.Sugar().Error("GetContractSymbolOnMainNet err ", token, err)
-		return err, ""
+	unpackString := func(result multicall.Result) (string, error) {
+		var text string
+		err := result.Unpack(&text)
+		if err == nil {
+			return text, nil
+		}
+		var text32 [32]byte
+		if result.UnpackWith(erc20Bytes32Abi, &text32) != nil {
+			return "", err
+		}
+		return bytes32ToString(text32), nil
 	}
 
-	res := make([]interface{}, 0)
-	err = contract.Call(nil, &res, "symbol")
-	if err != nil {
-		log.Logger.Sugar().Error("GetContractSymbolOnMainNet err ", err)
-		return err, ""
+	metadata := make(map[string]TokenMetadata, len(tokens))
+	for i, token := range tokens {
+		symbol, err := unpackString(results[3*i])
+		if err != nil {
+			log.Logger.Sugar().Error("GetTokensMetadata symbol err ", chain.ChainId, " ", token, " ", err)
+			continue
+		}
+		name, err := unpackString(results[3*i+1])
+		if err != nil {
+			log.Logger.Sugar().Info("GetTokensMetadata no name ", chain.ChainId, " ", token, " ", err)
+		}
+		var decimals uint8
+		err = results[3*i+2].Unpack(&decimals)
+		if err != nil {
+			log.Logger.Sugar().Error("GetTokensMetadata decimals err ", chain.ChainId, " ", token, " ", err)
+			continue
+		}
+		metadata[token] = TokenMetadata{Name: name, Symbol: symbol, Decimals: int(decimals)}
 	}
-
-	return nil, res[0].(string)
+	return metadata, nil
 }
 
-// GetContractSymbolOnTestNet get contract symbol on test net
-func (s *TokenSymbol) GetContractSymbolOnTestNet(token, network string) (error, string) {
-	ethereumConn, err := ethclient.Dial(network)
-	if nil != err {
-		log.Logger.Sugar().Error("GetContractSymbolOnMainNet err ", token, err)
-		return err, ""
-	}
-	abiStr, err := abifile.GetAbiByToken("erc20")
-	if err != nil {
-		log.Logger.Sugar().Error("GetContractSymbolOnMainNet err ", token, err)
-		return err, ""
-	}
-	parsed, err := abi.JSON(strings.NewReader(abiStr))
-	if err != nil {
-		log.Logger.Sugar().Error("GetContractSymbolOnMainNet err ", token, err)
-		return err, ""
-	}
-	contract, err := bind.NewBoundContract(common.HexToAddress(token), parsed, ethereumConn, ethereumConn, ethereumConn), nil
-	if err != nil {
-		log.Logger.Sugar().Error("GetContractSymbolOnMainNet err ", token, err)
-		return err, ""
-	}
-
-	res := make([]interface{}, 0)
-	err = contract.Call(nil, &res, "symbol")
-	if err != nil {
-		log.Logger.Sugar().Error("GetContractSymbolOnMainNet err ", token, err)
-		return err, ""
-	}
-
-	return nil, res[0].(string)
+// bytes32ToString the text of a right zero padded bytes32
+func bytes32ToString(b [32]byte) string {
+	return strings.TrimSpace(string(bytes.TrimRight(b[:], "\x00")))
 }
 
 // CheckSymbolData Saving symbol data to redis if it has new symbol
@@ -261,12 +220,14 @@ func (s *TokenSymbol) CheckTokenInfo(token, chainId string) error {
 	return nil
 }
 
-// SaveSymbolData Saving symbol data to mysql if it has new symbol
-func (s *TokenSymbol) SaveSymbolData(token, chainId, symbol string) error {
+// SaveSymbolData Saving symbol, name and decimals to mysql if they changed
+func (s *TokenSymbol) SaveSymbolData(token, chainId string, metadata TokenMetadata) error {
 	nowDateTime := utils.GetCurDateTimeFormat()
 
 	err := db.Mysql.Table("token_info").Where("token=? and chain_id=? ", token, chainId).Updates(map[string]interface{}{
-		"symbol":     symbol,
+		"symbol":     metadata.Symbol,
+		"name":       metadata.Name,
+		"decimals":   metadata.Decimals,
 		"updated_at": nowDateTime,
 	}).Debug().Error
 	if err != nil {
@@ -14,21 +14,26 @@ func Task() {
 	// get environment variables
 	common.GetEnv()
 
-	// flush redis db
-	err := db.RedisFlushDB()
-	if err != nil {
-		panic("clear redis error " + err.Error())
+	// clear the pool and token caches so they are written again, the db also holds locks, sessions and
+	// revoked tokens of the api and the price producer that have to survive a restart
+	for _, pattern := range []string{"base_info:pool_*", "data_info:pool_*", "token_info:*"} {
+		err := db.RedisDeleteByPattern(pattern)
+		if err != nil {
+			panic("clear redis error " + err.Error())
+		}
 	}
 
 	//init task
-	services.NewEthService().GetBlock()
+	go services.NewEthService().GetBlock()
 	services.NewPool().UpdateAllPoolInfo()
 	services.NewTokenPrice().UpdateContractPrice()
 	services.NewTokenSymbol().UpdateContractSymbol()
 	services.NewTokenLogo().UpdateTokenLogo()
 	services.NewBalanceMonitor().Monitor()
-	// services.NewTokenPrice().SavePlgrPrice()
-	services.NewTokenPrice().SavePlgrPriceTestNet()
+	go services.NewEventIndexer().IndexAllPoolEvents()
+	services.NewLiquidationMonitor().Monitor()
+	services.NewKeeper().Run()
+	services.NewOraclePusher().Run()
 
 	//run pool task
 	s := gocron.NewScheduler()
@@ -38,8 +43,12 @@ func Task() {
 	_ = s.Every(2).Hours().From(gocron.NextTick()).Do(services.NewTokenSymbol().UpdateContractSymbol)
 	_ = s.Every(2).Hours().From(gocron.NextTick()).Do(services.NewTokenLogo().UpdateTokenLogo)
 	_ = s.Every(30).Minutes().From(gocron.NextTick()).Do(services.NewBalanceMonitor().Monitor)
-	//_ = s.Every(30).Minutes().From(gocron.NextTick()).Do(services.NewTokenPrice().SavePlgrPrice)
-	_ = s.Every(30).Minutes().From(gocron.NextTick()).Do(services.NewTokenPrice().SavePlgrPriceTestNet)
+	_ = s.Every(1).Minute().From(gocron.NextTick()).Do(services.NewEventIndexer().IndexAllPoolEvents)
+	_ = s.Every(5).Minutes().From(gocron.NextTick()).Do(services.NewLiquidationMonitor().Monitor)
+	_ = s.Every(1).Minute().From(gocron.NextTick()).Do(services.NewKeeper().Run)
+	_ = s.Every(1).Minute().From(gocron.NextTick()).Do(services.NewOraclePusher().Run)
+	_ = s.Every(1).Hour().From(gocron.NextTick()).Do(services.NewTokenPrice().DownsamplePrices)
+	_ = s.Every(1).Minute().From(gocron.NextTick()).Do(services.NewBlockBackfill().Run)
 	// _ = s.Every(60).Seconds().From(gocron.NextTick()).Do(services.NewEthService().GetBlock)
 	<-s.Start() // Start all the pending jobs
 
@@ -1,29 +1,68 @@
 package utils
 
 import (
+	"errors"
 	"github.com/dgrijalva/jwt-go"
 	"pledge-backend/config"
 	"time"
 )
 
-func CreateToken(username string) (string, error) {
-	at := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"username": username,
-		"exp":      time.Now().Add(time.Hour * 24 * 30).Unix(),
-	})
-	token, err := at.SignedString([]byte(config.Config.Jwt.SecretKey))
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// TokenClaims claims of an access or refresh token, Sid is the login session the token belongs to
+type TokenClaims struct {
+	Username string `json:"username"`
+	Sid      string `json:"sid"`
+	Type     string `json:"typ"`
+	jwt.StandardClaims
+}
+
+// CreateToken short-lived access token, it expires after [jwt] expire_time seconds
+func CreateToken(username, sid string) (string, *TokenClaims, error) {
+	return createToken(username, sid, TokenTypeAccess, config.Config.Jwt.ExpireTime)
+}
+
+// CreateRefreshToken refresh token of a session, it expires after [jwt] refresh_expire_time seconds
+func CreateRefreshToken(username, sid string) (string, *TokenClaims, error) {
+	return createToken(username, sid, TokenTypeRefresh, config.Config.Jwt.RefreshExpireTime)
+}
+
+func createToken(username, sid, tokenType string, expireSeconds int) (string, *TokenClaims, error) {
+	now := time.Now()
+	claims := &TokenClaims{
+		Username: username,
+		Sid:      sid,
+		Type:     tokenType,
+		StandardClaims: jwt.StandardClaims{
+			Id:        UniqueId(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Duration(expireSeconds) * time.Second).Unix(),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.Config.Jwt.SecretKey))
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
-	return token, nil
+	return token, claims, nil
 }
 
-func ParseToken(token string, secret string) (string, error) {
-	claim, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+// ParseToken verify the signature and expiry of a token and that it is of tokenType
+func ParseToken(token string, secret string, tokenType string) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
 		return []byte(secret), nil
 	})
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if claims.Type != tokenType || claims.Id == "" || claims.Sid == "" {
+		return nil, errors.New("invalid token type")
 	}
-	return claim.Claims.(jwt.MapClaims)["username"].(string), nil
+	return claims, nil
 }